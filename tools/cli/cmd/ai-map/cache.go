@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cache"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/version"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// cacheFlags are shared by commands whose per-file results can be cached.
+type cacheFlags struct {
+	enable  bool
+	disable bool
+	dir     string
+}
+
+func addCacheFlags(fs *pflag.FlagSet, f *cacheFlags) {
+	fs.BoolVar(&f.enable, "cache", false, "Reuse results for unchanged files (or set "+cache.EnvEnable+"=1)")
+	fs.BoolVar(&f.disable, "no-cache", false, "Ignore the cache even if enabled by --cache or "+cache.EnvEnable)
+	fs.StringVar(&f.dir, "cache-dir", "", "Cache directory (defaults to $"+cache.EnvDir+" or the user cache dir)")
+}
+
+// open returns nil when caching is off.
+func (f cacheFlags) open() (*cache.Cache, error) {
+	if f.disable || !(f.enable || cache.EnabledByEnv()) {
+		return nil, nil
+	}
+	dir, err := f.resolveDir()
+	if err != nil {
+		return nil, cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+	}
+	return cache.Open(dir), nil
+}
+
+func (f cacheFlags) resolveDir() (string, error) {
+	if f.dir != "" {
+		return f.dir, nil
+	}
+	return cache.DefaultDir()
+}
+
+// cacheKey binds a result to the file content, the tool build and the
+// command's configuration fingerprint.
+func cacheKey(content []byte, fingerprint string) string {
+	return cache.Key(content, []byte(version.Version+"+"+version.Commit), []byte(fingerprint))
+}
+
+func newCacheCmd(stdout, stderr io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage the validate/lint result cache",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = cmd.Help()
+			return cli.ExitError{Code: cli.ExitUsageOrConfig}
+		},
+	}
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.AddCommand(newCacheCleanCmd(stdout, stderr))
+	return cmd
+}
+
+func newCacheCleanCmd(stdout, stderr io.Writer) *cobra.Command {
+	var f cacheFlags
+
+	cmd := &cobra.Command{
+		Use:   "clean [--cache-dir DIR]",
+		Short: "Remove all cached results",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir, err := f.resolveDir()
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+			n, err := cache.Clean(dir)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: cannot clean cache: " + err.Error()}
+			}
+			fmt.Fprintf(stderr, "removed %d cached result(s) from %s\n", n, dir)
+			return nil
+		},
+	}
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().StringVar(&f.dir, "cache-dir", "", "Cache directory (defaults to $"+cache.EnvDir+" or the user cache dir)")
+	return cmd
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCachedResultsMatchUncached(t *testing.T) {
+	dir := t.TempDir()
+	cacheDir := t.TempDir()
+	good := "version: 1\nsystem:\n  name: svc\n  purpose: A service\nextensions:\n  acme.gates: {}\n"
+	bad := "version: 9\nsystem:\n  name: has space\n"
+	if err := os.WriteFile(filepath.Join(dir, "a.yaml"), []byte(good), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "b.yaml"), []byte(bad), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(args ...string) string {
+		var stdout, stderr bytes.Buffer
+		cmd := newRootCmd(&stdout, &stderr)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		code, _, _ := exitCodeFromError(err)
+		return fmt.Sprintf("%s%sexit %d", stdout.String(), stderr.String(), code)
+	}
+
+	for _, name := range []string{"validate", "lint"} {
+		want := run(name, "--dir", dir, "--no-cache")
+		for i := 0; i < 2; i++ { // miss, then hit
+			if got := run(name, "--dir", dir, "--cache", "--cache-dir", cacheDir); got != want {
+				t.Fatalf("%s run %d with cache:\n%s\nwant:\n%s", name, i, got, want)
+			}
+		}
+	}
+
+	// A stricter configuration must not reuse the earlier (warning-only) result.
+	want := run("validate", "--dir", dir, "--no-cache", "--unknown-extensions", "error")
+	if got := run("validate", "--dir", dir, "--cache", "--cache-dir", cacheDir, "--unknown-extensions", "error"); got != want {
+		t.Fatalf("config change reused stale cache:\n%s\nwant:\n%s", got, want)
+	}
+
+	// Edited files miss the cache.
+	if err := os.WriteFile(filepath.Join(dir, "b.yaml"), []byte(good), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	want = run("lint", "--dir", dir, "--no-cache")
+	if got := run("lint", "--dir", dir, "--cache", "--cache-dir", cacheDir); got != want {
+		t.Fatalf("edited file reused stale cache:\n%s\nwant:\n%s", got, want)
+	}
+
+	if out := run("cache", "clean", "--cache-dir", cacheDir); !bytes.Contains([]byte(out), []byte("removed 6 cached result(s)")) {
+		t.Fatalf("cache clean: %s", out)
+	}
+}
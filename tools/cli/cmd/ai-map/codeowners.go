@@ -0,0 +1,153 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/codeowners"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/spf13/cobra"
+)
+
+// defaultTeamsFile maps ownership.team values to CODEOWNERS handles, relative to --root.
+const defaultTeamsFile = ".ai-map-teams.yaml"
+
+// codeownersLocations are checked in GitHub's lookup order.
+var codeownersLocations = []string{
+	filepath.Join(".github", "CODEOWNERS"),
+	"CODEOWNERS",
+	filepath.Join("docs", "CODEOWNERS"),
+}
+
+func newCodeownersCmd(stdout, stderr io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "codeowners",
+		Short: "Generate or check CODEOWNERS from map ownership",
+		Long: "Maps each .ai-map.yaml directory and its critical paths to the handles configured for\n" +
+			"its ownership.team in " + defaultTeamsFile + " (teams: {<team>: \"@org/handle\"}).",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = cmd.Help()
+			return cli.ExitError{Code: cli.ExitUsageOrConfig}
+		},
+	}
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.AddCommand(newCodeownersGenerateCmd(stdout, stderr))
+	cmd.AddCommand(newCodeownersCheckCmd(stdout, stderr))
+	return cmd
+}
+
+func newCodeownersGenerateCmd(stdout, stderr io.Writer) *cobra.Command {
+	var root, teamsPath, outPath string
+	var force bool
+
+	cmd := &cobra.Command{
+		Use:   "generate [--root DIR] [--teams FILE] [--out FILE [--force]]",
+		Short: "Emit a CODEOWNERS file from map ownership data",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := codeownersEntries(stderr, root, teamsPath)
+			if err != nil {
+				return err
+			}
+			out := codeowners.Format(entries)
+			if outPath == "" {
+				_, _ = stdout.Write(out)
+				return nil
+			}
+			absOut, err := filepath.Abs(outPath)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: invalid --out: " + err.Error()}
+			}
+			if _, err := os.Stat(absOut); err == nil && !force {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: refusing to overwrite existing file: " + absOut + " (use --force)"}
+			}
+			if err := os.MkdirAll(filepath.Dir(absOut), 0o755); err != nil {
+				return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: cannot create output dir: " + err.Error()}
+			}
+			if err := os.WriteFile(absOut, out, 0o644); err != nil {
+				return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: cannot write output: " + err.Error()}
+			}
+			return nil
+		},
+	}
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().StringVar(&root, "root", ".", "Repository root to scan for maps")
+	cmd.Flags().StringVar(&teamsPath, "teams", "", "Team handle config (defaults to <root>/"+defaultTeamsFile+")")
+	cmd.Flags().StringVar(&outPath, "out", "", "Output file (defaults to stdout)")
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite --out if it exists")
+	return cmd
+}
+
+func newCodeownersCheckCmd(stdout, stderr io.Writer) *cobra.Command {
+	var root, teamsPath, file string
+
+	cmd := &cobra.Command{
+		Use:   "check [--root DIR] [--teams FILE] [--file CODEOWNERS]",
+		Short: "Report where CODEOWNERS contradicts the maps",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			entries, err := codeownersEntries(stderr, root, teamsPath)
+			if err != nil {
+				return err
+			}
+			path := file
+			if path == "" {
+				for _, loc := range codeownersLocations {
+					if p := filepath.Join(root, loc); fileExists(p) {
+						path = p
+						break
+					}
+				}
+				if path == "" {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: no CODEOWNERS found under " + root + " (use --file)"}
+				}
+			}
+			b, err := os.ReadFile(path)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+
+			problems := codeowners.Check(entries, codeowners.Parse(b))
+			for _, p := range problems {
+				fmt.Fprintf(stderr, "%s: %s\n", path, p)
+			}
+			if len(problems) > 0 {
+				return cli.ExitError{Code: cli.ExitCheckFailed}
+			}
+			return nil
+		},
+	}
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().StringVar(&root, "root", ".", "Repository root to scan for maps")
+	cmd.Flags().StringVar(&teamsPath, "teams", "", "Team handle config (defaults to <root>/"+defaultTeamsFile+")")
+	cmd.Flags().StringVar(&file, "file", "", "CODEOWNERS file (defaults to .github/, root, then docs/)")
+	return cmd
+}
+
+func codeownersEntries(stderr io.Writer, root, teamsPath string) ([]codeowners.Entry, error) {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: invalid --root: " + err.Error()}
+	}
+	if teamsPath == "" {
+		teamsPath = filepath.Join(absRoot, defaultTeamsFile)
+	}
+	teams, err := codeowners.LoadTeams(teamsPath)
+	if err != nil {
+		return nil, cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", teamsPath, err)}
+	}
+	maps, err := aimap.LoadTree(absRoot, input.MaxYAMLBytes)
+	if err != nil {
+		return nil, cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+	}
+	entries, warnings := codeowners.Generate(maps, teams)
+	for _, w := range warnings {
+		fmt.Fprintf(stderr, "warning: %s\n", w)
+	}
+	return entries, nil
+}
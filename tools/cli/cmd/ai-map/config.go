@@ -0,0 +1,124 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/config"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/spf13/cobra"
+)
+
+// loadToolConfig reads --config, or the .ai-map-tool.yaml found from the working directory.
+func loadToolConfig(path string) (*config.Config, error) {
+	if path == "" {
+		p, ok := config.Find(".")
+		if !ok {
+			return &config.Config{}, nil
+		}
+		path = p
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		return nil, cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", path, err)}
+	}
+	return cfg, nil
+}
+
+// applyToolConfig sets the size cap and fills in every flag of cmd that the tool
+// config covers for that command and the user did not pass explicitly. Input
+// selection settings are skipped when files are given as arguments.
+func applyToolConfig(cmd *cobra.Command, args []string, cfg *config.Config, maxBytes int64) error {
+	input.MaxYAMLBytes = input.DefaultMaxYAMLBytes
+	if cmd.Flags().Changed("max-bytes") {
+		if maxBytes <= 0 {
+			return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --max-bytes must be > 0"}
+		}
+		input.MaxYAMLBytes = maxBytes
+	} else if n, ok := cfg.MaxBytes(); ok {
+		input.MaxYAMLBytes = n
+	}
+
+	name := strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+	for _, s := range cfg.Settings() {
+		if s.Source == config.SourceDefault || s.Flag == "max-bytes" || !s.AppliesTo(name) {
+			continue
+		}
+		if config.InputKeys[s.Key] && len(args) > 0 {
+			continue
+		}
+		f := cmd.Flags().Lookup(s.Flag)
+		if f == nil || f.Changed {
+			continue
+		}
+		for _, v := range s.Values {
+			if err := cmd.Flags().Set(s.Flag, v); err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s: %s", cfg.Path, s.Key, err)}
+			}
+		}
+	}
+	return nil
+}
+
+func newConfigCmd(stdout, stderr io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Inspect the project tool configuration (" + config.FileName + ")",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			_ = cmd.Help()
+			return cli.ExitError{Code: cli.ExitUsageOrConfig}
+		},
+	}
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.AddCommand(newConfigPrintCmd(stdout, stderr))
+	return cmd
+}
+
+func newConfigPrintCmd(stdout, stderr io.Writer) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "print",
+		Short: "Show the effective tool settings and where each comes from",
+		Long: "Prints every setting of " + config.FileName + " (found by walking up from the working directory,\n" +
+			"or --config) with its value and source. Unset settings use the command's flag default.\n" +
+			"Each setting defaults that flag of the listed commands only. Flags given on a command\n" +
+			"line always override these settings.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path, _ := cmd.Flags().GetString("config")
+			cfg, err := loadToolConfig(path)
+			if err != nil {
+				return err
+			}
+			if cfg.Path == "" {
+				fmt.Fprintf(stdout, "# no %s found; using defaults\n", config.FileName)
+			} else {
+				fmt.Fprintf(stdout, "# %s\n", cfg.Path)
+			}
+			for _, s := range cfg.Settings() {
+				source := s.Source
+				value := strings.Join(s.Values, ", ")
+				if s.Flag == "max-bytes" {
+					// Already resolved at startup; show the effective cap.
+					value = fmt.Sprint(input.MaxYAMLBytes)
+					if cmd.Flags().Changed("max-bytes") {
+						source = "flag --max-bytes"
+					}
+				}
+				if value == "" {
+					value = "-"
+				}
+				flag := "--" + s.Flag
+				if len(s.Commands) > 0 {
+					flag += " of " + strings.Join(s.Commands, ", ")
+				}
+				fmt.Fprintf(stdout, "%-20s %-40s # %s (%s)\n", s.Key, value, source, flag)
+			}
+			return nil
+		},
+	}
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	return cmd
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestToolConfigFormatIsScopedToCommands(t *testing.T) {
+	dir := t.TempDir()
+	mapPath := filepath.Join(dir, ".ai-map.yaml")
+	if err := os.WriteFile(mapPath, []byte("version: 1\nsystem:\n  name: svc\n  type: service\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	run := func(format string, args ...string) (string, int) {
+		cfgPath := filepath.Join(dir, ".ai-map-tool.yaml")
+		if err := os.WriteFile(cfgPath, []byte("format: "+format+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		var stdout, stderr bytes.Buffer
+		cmd := newRootCmd(&stdout, &stderr)
+		cmd.SetArgs(append([]string{"--config", cfgPath}, args...))
+		code, msg, _ := exitCodeFromError(cmd.Execute())
+		return stdout.String() + stderr.String() + msg, code
+	}
+
+	for _, format := range []string{"text", "json"} {
+		if out, code := run(format, "context", mapPath); code != 0 || !strings.HasPrefix(out, "# ") {
+			t.Fatalf("format: %s: context exit %d:\n%s", format, code, out)
+		}
+		if out, code := run(format, "export", "--format", "llms-txt", mapPath); code != 0 || !strings.HasPrefix(out, "# svc") {
+			t.Fatalf("format: %s: export exit %d:\n%s", format, code, out)
+		}
+		// export's --format stays required; the config value is not a fallback for it.
+		if out, _ := run(format, "export", mapPath); strings.Contains(out, "unknown --format") {
+			t.Fatalf("format: %s leaked into export:\n%s", format, out)
+		}
+	}
+
+	// Commands the key documents still pick it up.
+	if out, code := run("json", "drift", mapPath); code != 0 || !strings.HasPrefix(out, "{") {
+		t.Fatalf("drift with format: json, exit %d:\n%s", code, out)
+	}
+}
@@ -48,9 +48,13 @@ func newConformanceCmd(stdout, stderr io.Writer) *cobra.Command {
 				return nil
 			}
 
+			// Prefer a schema checked into the repo; otherwise fall back to the embedded
+			// per-version schemas.
 			sp := strings.TrimSpace(schemaPath)
 			if sp == "" {
-				sp = filepath.Join(absRoot, "spec", "ai-map.schema.json")
+				if p := filepath.Join(absRoot, "spec", "ai-map.schema.json"); fileExists(p) {
+					sp = p
+				}
 			}
 
 			v, err := validate.New(validate.Options{
@@ -100,7 +104,7 @@ func newConformanceCmd(stdout, stderr io.Writer) *cobra.Command {
 	cmd.SetOut(stdout)
 	cmd.SetErr(stderr)
 	cmd.Flags().StringVar(&repoRoot, "repo-root", ".", "Repository root (used to locate spec/examples)")
-	cmd.Flags().StringVar(&schemaPath, "schema", "", "Path to JSON Schema (defaults to <repo-root>/spec/ai-map.schema.json)")
+	cmd.Flags().StringVar(&schemaPath, "schema", "", "Path to JSON Schema (defaults to <repo-root>/spec/ai-map.schema.json, else the embedded schemas)")
 	cmd.Flags().BoolVar(&updateGolden, "update-golden", false, "Update golden files (off by default)")
 	return cmd
 }
@@ -126,3 +130,8 @@ func listYAMLFilesIfDir(dir string) ([]string, error) {
 	}
 	return out, nil
 }
+
+func fileExists(p string) bool {
+	st, err := os.Stat(p)
+	return err == nil && !st.IsDir()
+}
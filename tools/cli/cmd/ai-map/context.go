@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cjson"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/contextpack"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/spf13/cobra"
+)
+
+func newContextCmd(stdout, stderr io.Writer) *cobra.Command {
+	var focus string
+	var budget int
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "context [--focus PATH|PROTOCOL|KEYWORD] [--budget TOKENS] [--format markdown|json] [map file | directory]",
+		Short: "Print a prompt-ready context pack for a task",
+		Long: "Bundles a map's essentials (system summary, boundaries, critical and ignored paths as\n" +
+			"warnings, owners, dependencies and runtime) with a ranked list of its most relevant source\n" +
+			"files, within an approximate token budget (about four bytes a token, counting the pack\n" +
+			"itself and the listed files' sizes).\n\n" +
+			"--focus narrows the pack: an entrypoint protocol (e.g. http) ranks that protocol's files\n" +
+			"first; an existing path ranks files under it first; anything else is a keyword matched\n" +
+			"against file paths, then contents. Without a focus, files rank by the boundaries they\n" +
+			"fall under. Files under extensions.ai-flow.ignore, tests and nested maps' files are never\n" +
+			"listed. The output depends only on the map, the files and the flags.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format = strings.ToLower(format)
+			if format != "markdown" && format != "json" {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: unsupported --format (expected markdown|json)"}
+			}
+			if budget <= 0 {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --budget must be positive"}
+			}
+			arg := "."
+			if len(args) == 1 {
+				arg = args[0]
+			}
+			_, mapPath, err := detectTarget(arg)
+			if err == nil && mapPath == "" {
+				err = fmt.Errorf("no %s found", aimap.FileName)
+			}
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", arg, err)}
+			}
+			label := displayPath(mapPath)
+			b, err := input.ReadFileWithLimit(mapPath, input.MaxYAMLBytes)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", label, err)}
+			}
+			m, err := aimap.Parse(b)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitCheckFailed, Msg: fmt.Sprintf("%s: error: %s", label, err)}
+			}
+			pack, err := contextpack.Build(label, filepath.Dir(mapPath), m, contextpack.Options{Focus: focus, Budget: budget})
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitInternalError, Msg: fmt.Sprintf("%s: error: %s", label, err)}
+			}
+
+			if format == "json" {
+				out, err := cjson.MarshalIndent(pack.JSON(), "", "  ")
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+				}
+				_, _ = stdout.Write(out)
+			} else {
+				_, _ = stdout.Write(pack.Markdown())
+			}
+			if pack.Omitted > 0 {
+				fmt.Fprintf(stderr, "context: %d relevant file(s) left out to stay within --budget %d\n", pack.Omitted, budget)
+			}
+			return nil
+		},
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().StringVar(&focus, "focus", "", "Path, entrypoint protocol or keyword to focus on")
+	cmd.Flags().IntVar(&budget, "budget", contextpack.DefaultBudget, "Approximate token budget")
+	cmd.Flags().StringVar(&format, "format", "markdown", "Output format: markdown|json")
+	return cmd
+}
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cjson"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/coverage"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/spf13/cobra"
+)
+
+func newCoverageCmd(stdout, stderr io.Writer) *cobra.Command {
+	var depth int
+	var minPct float64
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "coverage [--depth N] [--min PCT] [--format text|json] [dir]",
+		Short: "Report how much source code the maps' entrypoints, models and critical paths cover",
+		Long: "Walks dir (default: the current directory) as git sees it and classifies every source\n" +
+			"file that is not a test against the deepest map governing it: critical, entrypoint or\n" +
+			"model when one of that map's boundaries matches it (tested in that order), uncovered\n" +
+			"otherwise, including files no map governs. Files under extensions.ai-flow.ignore are\n" +
+			"out of scope and not counted.\n\n" +
+			"Prints per-directory (cut to --depth segments) and total percentages. With --min, exits 1\n" +
+			"when the total falls below PCT percent.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format = strings.ToLower(format)
+			if format != "text" && format != "json" {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: unsupported --format (expected text|json)"}
+			}
+			if depth < 1 {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --depth must be at least 1"}
+			}
+			if minPct < 0 || minPct > 100 {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --min must be between 0 and 100"}
+			}
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			root, err := filepath.Abs(dir)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+			maps, err := aimap.LoadTree(root, input.MaxYAMLBytes)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+			rep, err := coverage.Compute(root, maps)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+
+			total := rep.Total()
+			switch format {
+			case "text":
+				writeCoverageText(stdout, rep.Dirs(depth), total)
+			case "json":
+				out, err := cjson.MarshalIndent(coverageJSON(rep, depth, minPct), "", "  ")
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+				}
+				_, _ = stdout.Write(out)
+			}
+
+			if cmd.Flags().Changed("min") && total.Percent() < minPct {
+				fmt.Fprintf(stderr, "coverage: %.1f%% is below --min %g%%\n", total.Percent(), minPct)
+				return cli.ExitError{Code: cli.ExitCheckFailed}
+			}
+			return nil
+		},
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().IntVar(&depth, "depth", 1, "Directory segments per row")
+	cmd.Flags().Float64Var(&minPct, "min", 0, "Fail when total coverage is below this percentage")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text|json")
+	return cmd
+}
+
+var coverageClasses = []coverage.Class{coverage.ClassEntrypoint, coverage.ClassModel, coverage.ClassCritical, coverage.ClassUncovered}
+
+func writeCoverageText(w io.Writer, dirs []coverage.Stat, total coverage.Stat) {
+	width := len("DIRECTORY")
+	for _, s := range dirs {
+		width = max(width, len(s.Dir))
+	}
+	fmt.Fprintf(w, "%-*s  %6s  %10s  %6s  %8s  %9s  %8s\n", width, "DIRECTORY", "FILES", "ENTRYPOINT", "MODEL", "CRITICAL", "UNCOVERED", "COVERAGE")
+	row := func(name string, s coverage.Stat) {
+		fmt.Fprintf(w, "%-*s  %6d  %10d  %6d  %8d  %9d  %7.1f%%\n", width, name, s.Total,
+			s.Counts[coverage.ClassEntrypoint], s.Counts[coverage.ClassModel], s.Counts[coverage.ClassCritical], s.Counts[coverage.ClassUncovered], s.Percent())
+	}
+	for _, s := range dirs {
+		row(s.Dir, s)
+	}
+	row("total", total)
+}
+
+func coverageJSON(rep coverage.Report, depth int, minPct float64) map[string]any {
+	stat := func(s coverage.Stat) map[string]any {
+		counts := map[string]any{}
+		for _, c := range coverageClasses {
+			counts[string(c)] = s.Counts[c]
+		}
+		return map[string]any{
+			"dir":     s.Dir,
+			"files":   s.Total,
+			"counts":  counts,
+			"percent": math.Round(s.Percent()*10) / 10,
+		}
+	}
+	dirs := make([]any, 0)
+	for _, s := range rep.Dirs(depth) {
+		dirs = append(dirs, stat(s))
+	}
+	uncovered := make([]any, 0)
+	for _, f := range rep.Files {
+		if f.Class == coverage.ClassUncovered {
+			uncovered = append(uncovered, f.Path)
+		}
+	}
+	return map[string]any{
+		"total":     stat(rep.Total()),
+		"dirs":      dirs,
+		"uncovered": uncovered,
+		"min":       minPct,
+	}
+}
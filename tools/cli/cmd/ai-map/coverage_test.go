@@ -0,0 +1,53 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestCoverageMin(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		".ai-map.yaml": "version: 1\nsystem:\n  name: shop\nboundaries:\n  entrypoints:\n    http: [src]\n  critical: [src/pay.go]\n",
+		"src/pay.go":   "",
+		"src/cart.go":  "",
+		"lib/util.go":  "",
+	} {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(args ...string) (string, string, int) {
+		var stdout, stderr bytes.Buffer
+		cmd := newRootCmd(&stdout, &stderr)
+		cmd.SetArgs(append([]string{"coverage"}, append(args, dir)...))
+		code, _, _ := exitCodeFromError(cmd.Execute())
+		return stdout.String(), stderr.String(), code
+	}
+
+	// 2 of 3 files covered: 66.7%.
+	out, _, code := run("--min", "60")
+	if code != 0 {
+		t.Fatalf("--min 60: exit %d\n%s", code, out)
+	}
+	total := out[strings.LastIndex(out, "\ntotal"):]
+	if fields := strings.Fields(total); len(fields) != 7 || fields[2] != "1" || fields[4] != "1" || fields[6] != "66.7%" {
+		t.Fatalf("total row = %q, want one entrypoint and one critical file at 66.7%%", total)
+	}
+
+	_, stderr, code := run("--min", "70")
+	if code != 1 || !strings.Contains(stderr, "coverage: 66.7% is below --min 70%") {
+		t.Fatalf("--min 70: exit %d, stderr %q", code, stderr)
+	}
+
+	if _, _, code := run("--min", "101"); code != 2 {
+		t.Fatalf("--min 101: exit %d, want 2", code)
+	}
+}
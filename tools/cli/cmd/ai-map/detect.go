@@ -0,0 +1,148 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/detect"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/spf13/cobra"
+)
+
+func newDetectCmd(stdout, stderr io.Writer) *cobra.Command {
+	var check bool
+	var prefixes []string
+	var root string
+
+	cmd := &cobra.Command{
+		Use:   "detect [--check] [map file | directory]...",
+		Short: "Derive map fields from compose files, module manifests and CI config and report where maps disagree",
+		Long: "Reads the files that already describe a system and prints what they imply as a draft map\n" +
+			"fragment (each value commented with its source):\n\n" +
+			"  compose  " + strings.Join(detect.ComposeFiles, ", ") + ": well-known images become\n" +
+			"           dependencies.external, env_file entries runtime.config_paths, and a service\n" +
+			"           built from the directory runtime.environment: container.\n" +
+			"  manifests  " + strings.Join(detect.ManifestFiles, ", ") + ": modules under an\n" +
+			"           --internal-prefix (e.g. github.com/ourorg/*, @ourorg/*) become\n" +
+			"           dependencies.internal, named after the system.name of a map under --root when\n" +
+			"           one matches. Declared internal dependencies no manifest requires are reported too.\n" +
+			"  deploy   .github/workflows/*.yml and .gitlab-ci.yml at the repository root (only those\n" +
+			"           naming the directory, for a nested system), cdk.json, *.tf and serverless.yml:\n" +
+			"           runtime.deploys_via, with deploy targets (aws, kubernetes, ...) in the comment.\n" +
+			"           A workflow running cdk deploy suggests both github-actions and cdk; a map may\n" +
+			"           declare either.\n\n" +
+			"Each argument is a map file or a directory (default: the current directory). When a map\n" +
+			"exists, its disagreements with the findings are reported as warnings; --check makes them fail.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+			opt := detect.Options{InternalPrefixes: prefixes}
+			if len(prefixes) > 0 {
+				systems, err := knownSystems(root)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --root: " + err.Error()}
+				}
+				opt.Systems = systems
+			}
+			var mismatched bool
+			for i, arg := range args {
+				dir, mapPath, err := detectTarget(arg)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", arg, err)}
+				}
+				r, err := detect.Detect(dir, opt)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitCheckFailed, Msg: fmt.Sprintf("%s: error: %s", arg, err)}
+				}
+				frag, err := r.Fragment()
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+				}
+				label := arg
+				if mapPath != "" {
+					label = displayPath(mapPath)
+				}
+				if len(frag) == 0 {
+					fmt.Fprintf(stderr, "%s: nothing detected\n", label)
+				} else {
+					if i > 0 {
+						fmt.Fprintln(stdout)
+					}
+					fmt.Fprintf(stdout, "# %s: detected\n", label)
+					_, _ = stdout.Write(frag)
+				}
+
+				// Compare even when nothing was found: declared internal
+				// dependencies no manifest requires are still a mismatch.
+				if mapPath == "" {
+					continue
+				}
+				b, err := input.ReadFileWithLimit(mapPath, input.MaxYAMLBytes)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", label, err)}
+				}
+				m, err := aimap.Parse(b)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitCheckFailed, Msg: fmt.Sprintf("%s: error: %s", label, err)}
+				}
+				for _, mm := range detect.Compare(m, r) {
+					mismatched = true
+					fmt.Fprintf(stderr, "%s: warning: %s (%s)\n", label, mm.Message, mm.Field)
+				}
+			}
+			if check && mismatched {
+				return cli.ExitError{Code: cli.ExitCheckFailed}
+			}
+			return nil
+		},
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().BoolVar(&check, "check", false, "Exit non-zero when a map disagrees with the findings")
+	cmd.Flags().StringArrayVar(&prefixes, "internal-prefix", nil, "Module prefix of internal dependencies, e.g. github.com/ourorg/* (repeatable)")
+	cmd.Flags().StringVar(&root, "root", ".", "Repository root whose maps name the known systems")
+	return cmd
+}
+
+// detectTarget resolves an argument to the directory to inspect and the map in
+// it ("" when there is none).
+func detectTarget(arg string) (dir, mapPath string, err error) {
+	st, err := os.Stat(arg)
+	if err != nil {
+		return "", "", err
+	}
+	if !st.IsDir() {
+		return filepath.Dir(arg), arg, nil
+	}
+	p := filepath.Join(arg, aimap.FileName)
+	if st, err := os.Stat(p); err == nil && !st.IsDir() {
+		return arg, p, nil
+	}
+	return arg, "", nil
+}
+
+// knownSystems returns the system.name of every map under root.
+func knownSystems(root string) ([]string, error) {
+	maps, err := aimap.LoadTree(root, input.MaxYAMLBytes)
+	if err != nil {
+		return nil, err
+	}
+	var out []string
+	for _, m := range maps {
+		if m.Map.System.Name != "" {
+			out = append(out, m.Map.System.Name)
+		}
+	}
+	return out, nil
+}
+
+func displayPath(p string) string {
+	return filepath.ToSlash(filepath.Clean(p))
+}
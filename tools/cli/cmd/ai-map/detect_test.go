@@ -0,0 +1,28 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDetectCheckReportsUnusedInternalDependency(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{
+		".ai-map.yaml": "version: 1\nsystem:\n  name: orders\ndependencies:\n  internal: [payments]\n",
+		"go.mod":       "module github.com/ourorg/orders\n\ngo 1.22\n\nrequire github.com/spf13/cobra v1.8.0\n",
+	} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var stdout, stderr bytes.Buffer
+	cmd := newRootCmd(&stdout, &stderr)
+	cmd.SetArgs([]string{"detect", "--check", "--root", dir, "--internal-prefix", "github.com/ourorg/*", dir})
+	code, _, _ := exitCodeFromError(cmd.Execute())
+	if code != 1 || !strings.Contains(stderr.String(), "lists payments but no manifest (go.mod) requires it") {
+		t.Fatalf("exit %d, stdout %q, stderr %q", code, stdout.String(), stderr.String())
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/diff"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/git"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/spf13/cobra"
+)
+
+func newDiffCmd(stdout, stderr io.Writer) *cobra.Command {
+	var format string
+	var fromRev string
+	var toRev string
+	var failOnRisk bool
+
+	cmd := &cobra.Command{
+		Use:   "diff [--format text|json|markdown] (OLD NEW | --from REV [--to REV] FILE)",
+		Short: "Show semantic changes between two versions of a map",
+		Long: "Compares two AI-Map files field by field, or one file at two git revisions.\n" +
+			"With --from and no --to, the revision is compared against the working tree.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var oldName, newName string
+			var oldBytes, newBytes []byte
+			var err error
+
+			switch {
+			case fromRev == "" && toRev != "":
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --to requires --from"}
+			case fromRev != "":
+				if len(args) != 1 {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --from expects exactly one file"}
+				}
+				p := args[0]
+				oldName = fromRev + ":" + p
+				if oldBytes, err = git.Show(fromRev, p, input.MaxYAMLBytes); err != nil {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+				}
+				if toRev == "" {
+					newName = p
+					newBytes, err = input.ReadFileWithLimit(p, input.MaxYAMLBytes)
+				} else {
+					newName = toRev + ":" + p
+					newBytes, err = git.Show(toRev, p, input.MaxYAMLBytes)
+				}
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+				}
+			default:
+				if len(args) != 2 {
+					_ = cmd.Help()
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: expected OLD and NEW files"}
+				}
+				oldName, newName = args[0], args[1]
+				if oldBytes, err = input.ReadFileWithLimit(oldName, input.MaxYAMLBytes); err != nil {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", oldName, err)}
+				}
+				if newBytes, err = input.ReadFileWithLimit(newName, input.MaxYAMLBytes); err != nil {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", newName, err)}
+				}
+			}
+
+			rep, err := diff.CompareYAML(oldBytes, newBytes)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitCheckFailed, Msg: "error: " + err.Error()}
+			}
+
+			var out []byte
+			switch strings.ToLower(format) {
+			case "text":
+				out = diff.Text(rep)
+			case "json":
+				if out, err = diff.JSON(rep); err != nil {
+					return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+				}
+			case "markdown", "md":
+				out = diff.Markdown(rep, fmt.Sprintf("AI-Map changes: %s → %s", oldName, newName))
+			default:
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: unsupported --format (expected text|json|markdown)"}
+			}
+			if _, err := stdout.Write(out); err != nil {
+				return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+			}
+
+			if failOnRisk && rep.HasRisk() {
+				return cli.ExitError{Code: cli.ExitCheckFailed}
+			}
+			return nil
+		},
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text|json|markdown")
+	cmd.Flags().StringVar(&fromRev, "from", "", "Git revision of the old version (reads FILE via local git)")
+	cmd.Flags().StringVar(&toRev, "to", "", "Git revision of the new version (defaults to the working tree)")
+	cmd.Flags().BoolVar(&failOnRisk, "fail-on-risk", false, "Exit 1 when a risk-relevant change is found")
+	return cmd
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cjson"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/drift"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/spf13/cobra"
+)
+
+func newDriftCmd(stdout, stderr io.Writer) *cobra.Command {
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "drift [--format text|json] [map file | directory]...",
+		Short: "Report where a map contradicts the repository",
+		Long: "Compares each map with the files under its directory (as git sees them, without nested\n" +
+			"maps or extensions.ai-flow.ignore paths) and lists contradictions, most serious first:\n\n" +
+			"  high    boundary paths that do not exist; a system.language with no source files\n" +
+			"  medium  a system.language most source files disagree with; entrypoint directories\n" +
+			"          without source files; top-level source directories no boundary covers\n" +
+			"  low     an unset system.language; configuration files runtime.config_paths misses\n\n" +
+			"Each finding comes with a suggested edit. Each argument is a map file or a directory\n" +
+			"containing one (default: the current directory).",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format = strings.ToLower(format)
+			if format != "text" && format != "json" {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: unsupported --format (expected text|json)"}
+			}
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+			var reports []any
+			for i, arg := range args {
+				_, mapPath, err := detectTarget(arg)
+				if err == nil && mapPath == "" {
+					err = fmt.Errorf("no %s found", aimap.FileName)
+				}
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", arg, err)}
+				}
+				label := displayPath(mapPath)
+				b, err := input.ReadFileWithLimit(mapPath, input.MaxYAMLBytes)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", label, err)}
+				}
+				m, err := aimap.Parse(b)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitCheckFailed, Msg: fmt.Sprintf("%s: error: %s", label, err)}
+				}
+				findings, err := drift.Check(filepath.Dir(mapPath), m)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitInternalError, Msg: fmt.Sprintf("%s: error: %s", label, err)}
+				}
+				if format == "json" {
+					reports = append(reports, driftJSON(label, findings))
+					continue
+				}
+				if i > 0 {
+					fmt.Fprintln(stdout)
+				}
+				writeDriftText(stdout, label, findings)
+			}
+			if format == "json" {
+				out, err := cjson.MarshalIndent(map[string]any{"maps": reports}, "", "  ")
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+				}
+				_, _ = stdout.Write(out)
+			}
+			return nil
+		},
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text|json")
+	return cmd
+}
+
+func writeDriftText(w io.Writer, label string, findings []drift.Finding) {
+	if len(findings) == 0 {
+		fmt.Fprintf(w, "%s: no drift\n", label)
+		return
+	}
+	fmt.Fprintf(w, "%s: %d finding(s)\n", label, len(findings))
+	for i, f := range findings {
+		fmt.Fprintf(w, "%3d. [%s] %s\n", i+1, f.Severity, f.Message)
+		fmt.Fprintf(w, "     suggest: %s\n", f.Suggestion)
+	}
+}
+
+func driftJSON(label string, findings []drift.Finding) map[string]any {
+	list := make([]any, 0, len(findings))
+	for i, f := range findings {
+		list = append(list, map[string]any{
+			"rank":       i + 1,
+			"severity":   f.Severity,
+			"kind":       f.Kind,
+			"field":      f.Field,
+			"path":       f.Path,
+			"message":    f.Message,
+			"suggestion": f.Suggestion,
+		})
+	}
+	return map[string]any{"map": label, "findings": list}
+}
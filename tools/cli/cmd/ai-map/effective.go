@@ -0,0 +1,116 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cjson"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/git"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/resolve"
+	"github.com/spf13/cobra"
+)
+
+func newEffectiveCmd(stdout, stderr io.Writer) *cobra.Command {
+	var root string
+
+	cmd := &cobra.Command{
+		Use:   "effective [--root DIR] [DIR]",
+		Short: "Print the effective map for a directory, merged from nested maps",
+		Long: "Merges every .ai-map.yaml from the repository root down to DIR (default \".\").\n" +
+			"ownership, runtime and dependencies are inherited field by field unless overridden;\n" +
+			"everything else comes from the nearest map. Paths are rebased to the repository root.\n" +
+			"Prints canonical JSON with the source map of each field.",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dir := "."
+			if len(args) == 1 {
+				dir = args[0]
+			}
+			absDir, err := filepath.Abs(dir)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: invalid dir: " + err.Error()}
+			}
+			if st, err := os.Stat(absDir); err != nil || !st.IsDir() {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: not a directory: " + absDir}
+			}
+			if real, err := filepath.EvalSymlinks(absDir); err == nil {
+				absDir = real
+			}
+
+			absRoot, err := effectiveRoot(root, absDir)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+			if _, inside := aimap.RelTo(absRoot, absDir); !inside {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("error: %s is outside root %s", absDir, absRoot)}
+			}
+
+			chain, err := resolve.Chain(absRoot, absDir)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+			}
+			if len(chain) == 0 {
+				return cli.ExitError{Code: cli.ExitCheckFailed, Msg: "error: no " + aimap.FileName + " governs " + absDir}
+			}
+
+			layers := make([]resolve.Layer, 0, len(chain))
+			for _, p := range chain {
+				b, err := input.ReadFileWithLimit(p, input.MaxYAMLBytes)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", p, err)}
+				}
+				doc, err := aimap.Decode(b)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitCheckFailed, Msg: fmt.Sprintf("%s: error: %s", p, err)}
+				}
+				rel, _ := aimap.RelTo(absRoot, p)
+				relDir, _ := aimap.RelTo(absRoot, filepath.Dir(p))
+				layers = append(layers, resolve.Layer{Path: rel, Dir: relDir, Doc: doc})
+			}
+
+			eff := resolve.Resolve(layers)
+			chainOut := make([]any, 0, len(eff.Chain))
+			for _, c := range eff.Chain {
+				chainOut = append(chainOut, c)
+			}
+			sources := make(map[string]any, len(eff.Sources))
+			for k, v := range eff.Sources {
+				sources[k] = v
+			}
+			out, err := cjson.MarshalIndent(map[string]any{
+				"chain":     chainOut,
+				"effective": eff.Doc,
+				"sources":   sources,
+			}, "", "  ")
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+			}
+			_, _ = stdout.Write(out)
+			return nil
+		},
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().StringVar(&root, "root", "", "Repository root (defaults to the git work tree root, else DIR)")
+	return cmd
+}
+
+func effectiveRoot(flagRoot, dir string) (string, error) {
+	if flagRoot != "" {
+		return filepath.Abs(flagRoot)
+	}
+	if top, err := git.TopLevel(dir); err == nil {
+		// Resolve symlinks on both sides so RelTo compares like with like.
+		if real, err := filepath.EvalSymlinks(top); err == nil {
+			top = real
+		}
+		return top, nil
+	}
+	return dir, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/backstage"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/llmstxt"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/textdiff"
+	"github.com/spf13/cobra"
+)
+
+func newExportCmd(stdout, stderr io.Writer) *cobra.Command {
+	var sel input.Selection
+	var format string
+	var outPath string
+	var bs backstage.Options
+	var check bool
+
+	cmd := &cobra.Command{
+		Use:   "export --format backstage|llms-txt [--out FILE] [--check] [--dir DIR] [--recursive | --discover] [files... | - | REV:file]",
+		Short: "Export maps to another catalog format",
+		Long: "Formats:\n" +
+			"  backstage  a catalog-info.yaml Component per map (one YAML document each): system.name,\n" +
+			"             type and domain, ownership.team as spec.owner, dependencies.internal as\n" +
+			"             spec.dependsOn and ownership.docs as metadata.links.\n" +
+			"  llms-txt   an llms.txt (https://llmstxt.org) for exactly one map: system.name as the title,\n" +
+			"             type, domain, language and owner as the summary, critical and ignored paths\n" +
+			"             flagged up front, then entrypoints, models, docs (runbook, ADRs, OpenAPI) and\n" +
+			"             config paths as link sections. Links are relative to the map's directory.\n\n" +
+			"--check compares the export with the existing --out file (for llms-txt, llms.txt next to\n" +
+			"the map by default) instead of writing, and exits 1 with a diff when it is stale.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if format != "backstage" && format != "llms-txt" {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: unknown --format " + format + " (want backstage|llms-txt)"}
+			}
+			inputs, err := input.SelectFiles(sel, args)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+			if err := input.EnsureSelected(sel, inputs); err != nil {
+				_ = cmd.Help()
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+			if format == "llms-txt" && len(inputs) != 1 {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("error: llms-txt exports exactly one map (got %d)", len(inputs))}
+			}
+
+			var out []byte
+			for i, p := range inputs {
+				b, err := input.Read(p, input.MaxYAMLBytes)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+				}
+				m, err := aimap.Parse(b)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitCheckFailed, Msg: input.DisplayName(p) + ": error: " + err.Error()}
+				}
+				if format == "llms-txt" {
+					out = llmstxt.Generate(m, llmstxt.Options{BaseURL: bs.BaseURL})
+					continue
+				}
+				doc, err := backstage.Marshal(backstage.Export(m, bs))
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+				}
+				if i > 0 {
+					out = append(out, "---\n"...)
+				}
+				out = append(out, doc...)
+			}
+
+			if !check {
+				return writeOutput(stdout, outPath, out)
+			}
+			target := outPath
+			if target == "" && format == "llms-txt" && input.IsFile(inputs[0]) {
+				target = cwdRelative(filepath.Join(filepath.Dir(inputs[0]), llmstxt.FileName))
+			}
+			if target == "" {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --check needs --out FILE to compare with"}
+			}
+			return checkOutput(stdout, stderr, target, out, fmt.Sprintf("ai-map export --format %s %s > %s", format, cwdRelative(input.DisplayName(inputs[0])), target))
+		},
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().StringVar(&format, "format", "", "Output format: backstage|llms-txt")
+	cmd.Flags().StringVar(&outPath, "out", "", "Output file (defaults to stdout)")
+	cmd.Flags().StringVar(&bs.Lifecycle, "lifecycle", backstage.DefaultLifecycle, "backstage: spec.lifecycle for every component")
+	cmd.Flags().StringVar(&bs.BaseURL, "base-url", "", "backstage, llms-txt: URL prefix for repo-relative links (e.g. a repository browse URL)")
+	cmd.Flags().BoolVar(&check, "check", false, "Compare with the existing --out file instead of writing; exit 1 when it is stale")
+	_ = cmd.MarkFlagRequired("format")
+	input.AddFlags(cmd.Flags(), &sel)
+	return cmd
+}
+
+// checkOutput compares b with the file at target, printing a diff and how to
+// regenerate it (regen) when they differ.
+func checkOutput(stdout, stderr io.Writer, target string, b []byte, regen string) error {
+	cur, err := os.ReadFile(target)
+	if os.IsNotExist(err) {
+		fmt.Fprintf(stderr, "hint: create it with: %s\n", regen)
+		return cli.ExitError{Code: cli.ExitCheckFailed, Msg: "error: " + target + " does not exist"}
+	}
+	if err != nil {
+		return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+	}
+	if bytes.Equal(cur, b) {
+		fmt.Fprintf(stderr, "%s: up to date\n", target)
+		return nil
+	}
+	_, _ = stdout.Write(textdiff.Unified(target, target, cur, b))
+	fmt.Fprintf(stderr, "hint: regenerate it with: %s\n", regen)
+	return cli.ExitError{Code: cli.ExitCheckFailed, Msg: "error: " + target + " is stale"}
+}
+
+// writeOutput writes b to stdout, or to outPath when set (never overwriting).
+func writeOutput(stdout io.Writer, outPath string, b []byte) error {
+	if outPath == "" {
+		if _, err := stdout.Write(b); err != nil {
+			return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+		}
+		return nil
+	}
+	absOut, err := filepath.Abs(outPath)
+	if err != nil {
+		return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: invalid --out: " + err.Error()}
+	}
+	if _, err := os.Stat(absOut); err == nil {
+		return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: refusing to overwrite existing file: " + absOut}
+	}
+	if err := os.MkdirAll(filepath.Dir(absOut), 0o755); err != nil {
+		return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: cannot create output dir: " + err.Error()}
+	}
+	if err := os.WriteFile(absOut, b, 0o644); err != nil {
+		return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: cannot write output: " + err.Error()}
+	}
+	return nil
+}
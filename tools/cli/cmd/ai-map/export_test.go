@@ -0,0 +1,48 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportCheck(t *testing.T) {
+	dir := t.TempDir()
+	mapPath := filepath.Join(dir, ".ai-map.yaml")
+	target := filepath.Join(dir, "llms.txt")
+	writeMap := func(typ string) {
+		t.Helper()
+		if err := os.WriteFile(mapPath, []byte("version: 1\nsystem:\n  name: shop\n  type: "+typ+"\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	run := func(args ...string) (string, string, int) {
+		var stdout, stderr bytes.Buffer
+		cmd := newRootCmd(&stdout, &stderr)
+		cmd.SetArgs(append([]string{"export", "--format", "llms-txt"}, append(args, mapPath)...))
+		code, msg, _ := exitCodeFromError(cmd.Execute())
+		return stdout.String(), stderr.String() + msg, code
+	}
+	writeMap("service")
+
+	// Missing file: exit 1 with a hint to create it.
+	if _, stderr, code := run("--check"); code != 1 || !strings.Contains(stderr, "does not exist") || !strings.Contains(stderr, "hint: create it with:") {
+		t.Fatalf("missing: exit %d, stderr %q", code, stderr)
+	}
+
+	if _, stderr, code := run("--out", target); code != 0 {
+		t.Fatalf("--out: exit %d, stderr %q", code, stderr)
+	}
+	if stdout, stderr, code := run("--check"); code != 0 || stdout != "" || !strings.Contains(stderr, "up to date") {
+		t.Fatalf("up to date: exit %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+
+	// Stale file: exit 1 with a diff on stdout.
+	writeMap("library")
+	stdout, stderr, code := run("--check")
+	if code != 1 || !strings.Contains(stderr, "is stale") || !strings.Contains(stdout, "-> Service.") || !strings.Contains(stdout, "+> Library.") {
+		t.Fatalf("stale: exit %d, stdout %q, stderr %q", code, stdout, stderr)
+	}
+}
@@ -0,0 +1,208 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cjson"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/git"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/guard"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/spf13/cobra"
+)
+
+// guardApproveEnv carries sign-offs in CI, as a comma-separated team list.
+const guardApproveEnv = "AI_MAP_GUARD_APPROVE"
+
+func newGuardCmd(stdout, stderr io.Writer) *cobra.Command {
+	var base string
+	var failOn string
+	var approve []string
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "guard [--base REF] [--fail-on KINDS] [--approve TEAM]... [--format text|json]",
+		Short: "Block changes to critical paths without owner sign-off",
+		Long: "Matches changed files (staged files, or REF...HEAD with --base), deletions included,\n" +
+			"against every map that governs them and reports touched critical, model and config paths\n" +
+			"with their owning teams. Policy comes from the maps both before the change (HEAD, or the\n" +
+			"merge base with --base) and after it, so a change cannot lift its own protection.\n" +
+			"Exits 1 when a path of a --fail-on kind is touched and its team has not approved\n" +
+			"(via --approve or " + guardApproveEnv + "; \"*\" approves all).",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kinds, err := guard.ParseKinds(failOn)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --fail-on: " + err.Error()}
+			}
+			root, err := git.TopLevel(".")
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+
+			var files []string
+			if base != "" {
+				files, err = git.ChangedFiles(root, base)
+			} else {
+				files, err = git.StagedFiles(root)
+			}
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+
+			maps, err := guardMaps(root, base)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+
+			rep := guard.Check(maps, files)
+			approved := append([]string(nil), approve...)
+			for _, t := range strings.Split(os.Getenv(guardApproveEnv), ",") {
+				if t = strings.TrimSpace(t); t != "" {
+					approved = append(approved, t)
+				}
+			}
+			violations := rep.Violations(guard.Policy{FailOn: kinds, Approved: approved})
+
+			switch strings.ToLower(format) {
+			case "text":
+				writeGuardText(stdout, rep)
+			case "json":
+				out, err := cjson.MarshalIndent(guardJSON(rep, violations), "", "  ")
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+				}
+				_, _ = stdout.Write(out)
+			default:
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: unsupported --format (expected text|json)"}
+			}
+
+			if len(violations) == 0 {
+				return nil
+			}
+			seen := map[string]bool{}
+			var teams []string
+			for _, v := range violations {
+				if !seen[v.Team] {
+					seen[v.Team] = true
+					teams = append(teams, v.Team)
+				}
+			}
+			fmt.Fprintf(stderr, "guard: sign-off required from: %s\n", strings.Join(teams, ", "))
+			fmt.Fprintf(stderr, "hint: after review, rerun with --approve TEAM (or set %s)\n", guardApproveEnv)
+			return cli.ExitError{Code: cli.ExitCheckFailed}
+		},
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().StringVar(&base, "base", "", "Compare REF...HEAD instead of staged files")
+	cmd.Flags().StringVar(&failOn, "fail-on", string(guard.KindCritical), "Kinds that need sign-off: comma list of critical|model|config, or any|none")
+	cmd.Flags().StringArrayVar(&approve, "approve", nil, "Team that signed off (repeatable; \"*\" approves all)")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text|json")
+	cmd.AddCommand(newGuardInstallHookCmd(stdout, stderr))
+	return cmd
+}
+
+// guardMaps loads the maps in the work tree together with those at the
+// revision the change starts from: HEAD for staged files, the merge base for --base.
+func guardMaps(root, base string) ([]aimap.Located, error) {
+	maps, err := aimap.LoadTree(root, input.MaxYAMLBytes)
+	if err != nil {
+		return nil, err
+	}
+	rev := "HEAD"
+	if base != "" {
+		if rev, err = git.MergeBase(root, base); err != nil {
+			return nil, err
+		}
+	} else if !git.HasHEAD(root) {
+		return maps, nil // initial commit: nothing to compare against
+	}
+	old, err := aimap.LoadTreeAt(root, rev, input.MaxYAMLBytes)
+	if err != nil {
+		return nil, err
+	}
+	return append(old, maps...), nil
+}
+
+func newGuardInstallHookCmd(stdout, stderr io.Writer) *cobra.Command {
+	var force bool
+	var command string
+
+	cmd := &cobra.Command{
+		Use:   "install-hook [--force] [--command CMD]",
+		Short: "Register ai-map guard as the repository's pre-commit hook",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			hooks, err := git.HooksDir(".")
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+			hookPath := filepath.Join(hooks, "pre-commit")
+			if _, err := os.Stat(hookPath); err == nil && !force {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: refusing to overwrite existing hook: " + hookPath + " (use --force)"}
+			}
+			if err := os.MkdirAll(hooks, 0o755); err != nil {
+				return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: cannot create hooks dir: " + err.Error()}
+			}
+			script := "#!/bin/sh\n" +
+				"# Installed by `ai-map guard install-hook`.\n" +
+				"exec " + command + " guard\n"
+			if err := os.WriteFile(hookPath, []byte(script), 0o755); err != nil {
+				return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: cannot write hook: " + err.Error()}
+			}
+			fmt.Fprintf(stderr, "installed pre-commit hook: %s\n", hookPath)
+			return nil
+		},
+	}
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().BoolVar(&force, "force", false, "Overwrite an existing pre-commit hook")
+	cmd.Flags().StringVar(&command, "command", "ai-map", "Command the hook runs (must be on PATH or absolute)")
+	return cmd
+}
+
+func writeGuardText(w io.Writer, rep guard.Report) {
+	if len(rep.Touches) == 0 {
+		fmt.Fprintf(w, "guard: %d changed file(s); no governed boundaries touched\n", len(rep.Files))
+		return
+	}
+	for _, t := range rep.Touches {
+		fmt.Fprintf(w, "%-8s  %s  (%s: %s, team: %s)\n", t.Kind, t.File, t.MapPath, t.Pattern, t.Team)
+	}
+	fmt.Fprintf(w, "review required: %s\n", strings.Join(rep.Teams(), ", "))
+}
+
+func guardJSON(rep guard.Report, violations []guard.Touch) map[string]any {
+	touch := func(ts []guard.Touch) []any {
+		out := make([]any, 0, len(ts))
+		for _, t := range ts {
+			out = append(out, map[string]any{
+				"file":    t.File,
+				"kind":    string(t.Kind),
+				"pattern": t.Pattern,
+				"map":     t.MapPath,
+				"team":    t.Team,
+			})
+		}
+		return out
+	}
+	files := make([]any, 0, len(rep.Files))
+	for _, f := range rep.Files {
+		files = append(files, f)
+	}
+	teams := make([]any, 0)
+	for _, t := range rep.Teams() {
+		teams = append(teams, t)
+	}
+	return map[string]any{
+		"files":      files,
+		"touches":    touch(rep.Touches),
+		"teams":      teams,
+		"violations": touch(violations),
+	}
+}
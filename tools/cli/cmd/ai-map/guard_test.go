@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func gitIn(t *testing.T, dir string, args ...string) {
+	t.Helper()
+	cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("git %v: %v\n%s", args, err, out)
+	}
+}
+
+func writeIn(t *testing.T, dir, name, content string) {
+	t.Helper()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// guardRepo commits a map that marks pay.go critical and changes into the repo.
+func guardRepo(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	gitIn(t, dir, "init", "-q")
+	writeIn(t, dir, ".ai-map.yaml", "version: 1\nsystem:\n  name: svc\nownership:\n  team: payments\nboundaries:\n  critical: [pay.go]\n")
+	writeIn(t, dir, "pay.go", "package pay\n")
+	gitIn(t, dir, "add", "-A")
+	gitIn(t, dir, "commit", "-q", "-m", "init")
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+	return dir
+}
+
+func runGuard(t *testing.T) (string, int) {
+	t.Helper()
+	var stdout, stderr bytes.Buffer
+	cmd := newRootCmd(&stdout, &stderr)
+	cmd.SetArgs([]string{"guard"})
+	code, msg, _ := exitCodeFromError(cmd.Execute())
+	return stdout.String() + stderr.String() + msg, code
+}
+
+func TestGuardBlocksStagedDeletionOfCriticalFile(t *testing.T) {
+	dir := guardRepo(t)
+	gitIn(t, dir, "rm", "-q", "pay.go")
+	if out, code := runGuard(t); code != 1 {
+		t.Fatalf("exit %d, want 1:\n%s", code, out)
+	}
+}
+
+func TestGuardUsesMapsBeforeTheChange(t *testing.T) {
+	dir := guardRepo(t)
+	writeIn(t, dir, ".ai-map.yaml", "version: 1\nsystem:\n  name: svc\nownership:\n  team: payments\n")
+	writeIn(t, dir, "pay.go", "package pay\n\nfunc Pay() {}\n")
+	gitIn(t, dir, "add", "-A")
+	if out, code := runGuard(t); code != 1 {
+		t.Fatalf("dropping the critical entry with the change passed the guard (exit %d):\n%s", code, out)
+	}
+}
+
+func TestGuardBlocksStagedRenameOutOfCriticalPath(t *testing.T) {
+	dir := guardRepo(t)
+	if err := os.Mkdir(filepath.Join(dir, "other"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	gitIn(t, dir, "mv", "pay.go", "other/pay.go")
+	if out, code := runGuard(t); code != 1 {
+		t.Fatalf("moving a critical file away passed the guard (exit %d):\n%s", code, out)
+	}
+}
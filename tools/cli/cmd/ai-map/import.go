@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/backstage"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/openapi"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/textdiff"
+	"github.com/spf13/cobra"
+)
+
+func newImportCmd(stdout, stderr io.Writer) *cobra.Command {
+	var from string
+	var name string
+	var outPath string
+	var bs backstage.Options
+	var mapPath string
+	var write bool
+
+	cmd := &cobra.Command{
+		Use:   "import --from backstage|openapi [flags] <file>",
+		Short: "Draft or update a map from another source of truth",
+		Long: "Sources:\n" +
+			"  backstage  builds a draft .ai-map.yaml from a catalog-info.yaml Component. Only fields\n" +
+			"             with a clean counterpart are filled in; add boundaries and runtime by hand.\n" +
+			"  openapi    proposes boundaries.entrypoints.http for an existing map from a local OpenAPI 3\n" +
+			"             or Swagger 2 file, and records the file as ownership.docs.openapi. Handlers come\n" +
+			"             from " + strings.Join(openapi.HandlerKeys, "/") + " hints, else from functions named like\n" +
+			"             the operationId under the map's directory. Existing entries are kept and only\n" +
+			"             the touched lines change. Prints a diff; --write applies it.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch from {
+			case "backstage":
+				b, err := input.Read(args[0], input.MaxYAMLBytes)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+				}
+				entities, err := backstage.ParseEntities(b)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitCheckFailed, Msg: input.DisplayName(args[0]) + ": error: " + err.Error()}
+				}
+				e, err := backstage.SelectComponent(entities, name)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitCheckFailed, Msg: input.DisplayName(args[0]) + ": error: " + err.Error()}
+				}
+				out, err := backstage.Marshal(backstage.Import(e, bs))
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+				}
+				return writeOutput(stdout, outPath, out)
+			case "openapi":
+				if outPath != "" {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --out is not supported with --from openapi (it updates an existing map; use --write)"}
+				}
+				return importOpenAPI(stdout, stderr, args[0], mapPath, write)
+			default:
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: unknown --from " + from + " (want backstage|openapi)"}
+			}
+		},
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().StringVar(&from, "from", "", "Source format: backstage|openapi")
+	cmd.Flags().StringVar(&name, "name", "", "backstage: Component to import when the file holds several")
+	cmd.Flags().StringVar(&outPath, "out", "", "backstage: output file (defaults to stdout)")
+	cmd.Flags().StringVar(&bs.BaseURL, "base-url", "", "backstage: URL prefix to strip from doc links")
+	cmd.Flags().StringVar(&mapPath, "map", "", "openapi: map to update (defaults to the nearest "+aimap.FileName+" at or above the spec)")
+	cmd.Flags().BoolVar(&write, "write", false, "openapi: update the map in place instead of printing a diff")
+	_ = cmd.MarkFlagRequired("from")
+	return cmd
+}
+
+func importOpenAPI(stdout, stderr io.Writer, specArg, mapPath string, write bool) error {
+	if !input.IsFile(specArg) {
+		return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --from openapi needs a spec file on disk (handler paths are resolved relative to it)"}
+	}
+	specAbs, err := filepath.Abs(specArg)
+	if err != nil {
+		return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+	}
+	specBytes, err := input.ReadFileWithLimit(specAbs, input.MaxYAMLBytes)
+	if err != nil {
+		return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", specArg, err)}
+	}
+	spec, err := openapi.Parse(specBytes)
+	if err != nil {
+		return cli.ExitError{Code: cli.ExitCheckFailed, Msg: fmt.Sprintf("%s: error: %s", specArg, err)}
+	}
+
+	if mapPath == "" {
+		found, ok := nearestMap(filepath.Dir(specAbs))
+		if !ok {
+			return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: no " + aimap.FileName + " at or above " + filepath.Dir(specArg) + "; pass --map"}
+		}
+		mapPath = cwdRelative(found)
+	}
+	mapAbs, err := filepath.Abs(mapPath)
+	if err != nil {
+		return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: invalid --map: " + err.Error()}
+	}
+	mapBytes, err := input.ReadFileWithLimit(mapAbs, input.MaxYAMLBytes)
+	if err != nil {
+		return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", mapPath, err)}
+	}
+	mapDir := filepath.Dir(mapAbs)
+
+	sug, err := openapi.Suggest(spec, mapDir, filepath.Dir(specAbs))
+	if err != nil {
+		return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+	}
+	for _, m := range sug.Matches {
+		fmt.Fprintf(stderr, "%s -> %s (%s)\n", m.Operation, m.File, m.Via)
+	}
+	for _, op := range sug.Unmatched {
+		fmt.Fprintf(stderr, "%s: warning: no handler found for %s\n", specArg, op)
+	}
+	ambiguous := make([]string, 0, len(sug.Ambiguous))
+	for op := range sug.Ambiguous {
+		ambiguous = append(ambiguous, op)
+	}
+	sort.Strings(ambiguous)
+	for _, op := range ambiguous {
+		fmt.Fprintf(stderr, "%s: warning: %s matches several files (%s); add an x-handler hint\n", specArg, op, strings.Join(sug.Ambiguous[op], ", "))
+	}
+
+	specRel, err := filepath.Rel(mapDir, specAbs)
+	if err != nil {
+		return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+	}
+	merged, err := openapi.Merge(mapBytes, sug.Entrypoints, filepath.ToSlash(specRel))
+	if err != nil {
+		return cli.ExitError{Code: cli.ExitCheckFailed, Msg: fmt.Sprintf("%s: error: %s", mapPath, err)}
+	}
+
+	if !write {
+		if !bytes.Equal(mapBytes, merged) {
+			_, _ = stdout.Write(textdiff.Unified(mapPath, mapPath, mapBytes, merged))
+		} else {
+			fmt.Fprintf(stderr, "%s: up to date\n", mapPath)
+		}
+		return nil
+	}
+	if bytes.Equal(mapBytes, merged) {
+		fmt.Fprintf(stderr, "%s: up to date\n", mapPath)
+		return nil
+	}
+	if err := writeFileAtomic(mapAbs, merged); err != nil {
+		return cli.ExitError{Code: cli.ExitInternalError, Msg: fmt.Sprintf("%s: error: cannot write: %s", mapPath, err)}
+	}
+	fmt.Fprintf(stderr, "%s: updated (%d http entrypoint(s))\n", mapPath, len(sug.Entrypoints))
+	return nil
+}
+
+// nearestMap looks for a map file in dir and its ancestors.
+func nearestMap(dir string) (string, bool) {
+	for {
+		p := filepath.Join(dir, aimap.FileName)
+		if st, err := os.Stat(p); err == nil && !st.IsDir() {
+			return p, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// cwdRelative shows an absolute path relative to the working directory when it
+// lies beneath it.
+func cwdRelative(p string) string {
+	if wd, err := os.Getwd(); err == nil {
+		if rel, err := filepath.Rel(wd, p); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+	}
+	return p
+}
@@ -0,0 +1,19 @@
+package main
+
+import (
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/workpool"
+	"github.com/spf13/pflag"
+)
+
+// addJobsFlag registers --jobs for commands that process many files independently.
+func addJobsFlag(fs *pflag.FlagSet, jobs *int) {
+	fs.IntVar(jobs, "jobs", workpool.DefaultJobs(), "Files processed concurrently (output order is unaffected)")
+}
+
+func checkJobs(jobs int) error {
+	if jobs < 1 {
+		return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --jobs must be at least 1"}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestJobsOutputMatchesSerial checks that parallel runs print byte-identical
+// output to --jobs 1, including for invalid and lint-failing maps.
+func TestJobsOutputMatchesSerial(t *testing.T) {
+	dir := t.TempDir()
+	for i := 0; i < 60; i++ {
+		var doc string
+		switch i % 4 {
+		case 0:
+			doc = fmt.Sprintf("version: 1\nsystem:\n  name: svc-%02d\n  purpose: Service %d\n", i, i)
+		case 1:
+			doc = fmt.Sprintf("version: 1\nsystem:\n  name: svc-%02d\n  purpose: Service %d\nboundaries:\n  critical: [src/core]\n", i, i)
+		case 2:
+			doc = "version: 9\n"
+		case 3:
+			doc = fmt.Sprintf("system:\n  name: svc-%02d\n", i)
+		}
+		if err := os.WriteFile(filepath.Join(dir, fmt.Sprintf("m%02d.yaml", i)), []byte(doc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	valid := filepath.Join(dir, "valid")
+	if err := os.Mkdir(valid, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		doc := fmt.Sprintf("version: 1\nsystem:\n  name: svc-%02d\n  purpose: Service %d\n", i, i)
+		if err := os.WriteFile(filepath.Join(valid, fmt.Sprintf("v%02d.yaml", i)), []byte(doc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	run := func(args ...string) (string, string, error) {
+		var stdout, stderr bytes.Buffer
+		cmd := newRootCmd(&stdout, &stderr)
+		cmd.SetArgs(args)
+		err := cmd.Execute()
+		return stdout.String(), stderr.String(), err
+	}
+
+	for _, c := range [][]string{
+		{"validate", "--dir", dir},
+		{"lint", "--dir", dir},
+		{"render", "--dir", valid},
+	} {
+		serialOut, serialErr, serialRunErr := run(append(c, "--jobs", "1")...)
+		if serialOut == "" && serialErr == "" {
+			t.Fatalf("%s: serial run produced no output", c[0])
+		}
+		for _, jobs := range []string{"2", "8", "64"} {
+			out, errOut, runErr := run(append(c, "--jobs", jobs)...)
+			if out != serialOut || errOut != serialErr {
+				t.Fatalf("%s --jobs %s: output differs from serial run", c[0], jobs)
+			}
+			if fmt.Sprint(runErr) != fmt.Sprint(serialRunErr) {
+				t.Fatalf("%s --jobs %s: error %v, serial error %v", c[0], jobs, runErr, serialRunErr)
+			}
+		}
+	}
+
+	if _, _, err := run("lint", "--dir", dir, "--jobs", "0"); err == nil {
+		t.Fatal("expected --jobs 0 to be rejected")
+	}
+}
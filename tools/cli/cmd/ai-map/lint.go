@@ -3,18 +3,23 @@ package main
 import (
 	"fmt"
 	"io"
+	"strings"
 
 	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
 	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
 	"github.com/olddognewflex/ai-map/tools/cli/internal/lint"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/workpool"
 	"github.com/spf13/cobra"
 )
 
 func newLintCmd(stdout, stderr io.Writer) *cobra.Command {
 	var sel input.Selection
+	var jobs int
+	var cf cacheFlags
+	var rules []string
 
 	cmd := &cobra.Command{
-		Use:   "lint [--dir DIR] [--recursive] [files...]",
+		Use:   "lint [--rule NAME=off|warn|error]... [--jobs N] [--cache | --no-cache] [--dir DIR] [--recursive | --discover] [files... | - | REV:file]",
 		Short: "Run opinionated checks",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			inputs, err := input.SelectFiles(sel, args)
@@ -25,14 +30,54 @@ func newLintCmd(stdout, stderr io.Writer) *cobra.Command {
 				_ = cmd.Help()
 				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
 			}
+			if err := checkJobs(jobs); err != nil {
+				return err
+			}
+			c, err := cf.open()
+			if err != nil {
+				return err
+			}
+			fingerprint := lint.Fingerprint()
+			opt, err := lintOptions(rules)
+			if err != nil {
+				return err
+			}
 
-			var hadErrors bool
-			for _, p := range inputs {
-				b, err := input.ReadFileWithLimit(p, input.MaxYAMLBytes)
+			type outcome struct {
+				res lint.Result
+				b   []byte
+				err error
+			}
+			outcomes := workpool.Map(jobs, inputs, func(p string) outcome {
+				b, err := input.Read(p, input.MaxYAMLBytes)
 				if err != nil {
+					return outcome{err: err}
+				}
+				if c == nil {
+					return outcome{res: lint.LintYAMLBytes(b), b: b}
+				}
+				key := cacheKey(b, fingerprint)
+				var res lint.Result
+				if c.Get("lint", key, &res) {
+					return outcome{res: res, b: b}
+				}
+				res = lint.LintYAMLBytes(b)
+				// A failed write only costs a future cache miss.
+				_ = c.Put("lint", key, res)
+				return outcome{res: res, b: b}
+			})
+
+			var hadErrors bool
+			for i, arg := range inputs {
+				p := input.DisplayName(arg)
+				if err := outcomes[i].err; err != nil {
 					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", p, err)}
 				}
-				res := lint.LintYAMLBytes(b)
+				res := outcomes[i].res
+				if input.IsFile(arg) {
+					res.Issues = append(res.Issues, lint.LintFile(arg, outcomes[i].b, opt).Issues...)
+				}
+				res = opt.Apply(res)
 				for _, is := range res.Issues {
 					if is.Path != "" {
 						fmt.Fprintf(stderr, "%s: %s: %s (%s)\n", p, is.Severity, is.Message, is.Path)
@@ -53,9 +98,22 @@ func newLintCmd(stdout, stderr io.Writer) *cobra.Command {
 
 	cmd.SetOut(stdout)
 	cmd.SetErr(stderr)
-	cmd.Flags().StringVar(&sel.Dir, "dir", "", "Directory to scan for *.yml|*.yaml (non-recursive by default)")
-	cmd.Flags().BoolVar(&sel.Recursive, "recursive", false, "Scan directories recursively (off by default)")
+	cmd.Flags().StringArrayVar(&rules, "rule", nil, "Override a rule's severity, e.g. system-type=error (repeatable; rules: "+strings.Join(lint.Rules(), ", ")+")")
+	input.AddFlags(cmd.Flags(), &sel)
+	addJobsFlag(cmd.Flags(), &jobs)
+	addCacheFlags(cmd.Flags(), &cf)
 	return cmd
 }
 
-
+// lintOptions parses --rule settings.
+func lintOptions(rules []string) (lint.Options, error) {
+	opt := lint.Options{Rules: map[string]lint.Severity{}}
+	for _, r := range rules {
+		name, sev, err := lint.ParseRuleSetting(r)
+		if err != nil {
+			return lint.Options{}, cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --rule: " + err.Error()}
+		}
+		opt.Rules[name] = sev
+	}
+	return opt, nil
+}
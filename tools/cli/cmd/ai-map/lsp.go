@@ -0,0 +1,46 @@
+package main
+
+import (
+	"io"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/lsp"
+	"github.com/spf13/cobra"
+)
+
+func newLSPCmd(stdout, stderr io.Writer) *cobra.Command {
+	var schemaPath string
+	var extensionsDir string
+	var unknownExtensions string
+	var rules []string
+
+	cmd := &cobra.Command{
+		Use:   "lsp [--schema FILE] [--extensions-dir DIR] [--rule NAME=SEVERITY]...",
+		Short: "Run a Language Server Protocol server for AI-Map files over stdio",
+		Long: "Speaks LSP on stdin/stdout for editors (VS Code, Neovim, Cursor, ...): validate and lint\n" +
+			"diagnostics with ranges, completion of keys, enum values and boundary paths, hover docs\n" +
+			"from the spec, and quick-fixes for mistyped keys and values.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			v, err := newValidator(schemaPath, extensionsDir, unknownExtensions)
+			if err != nil {
+				return err
+			}
+			lintOpt, err := lintOptions(rules)
+			if err != nil {
+				return err
+			}
+			if err := lsp.Serve(cmd.InOrStdin(), stdout, lsp.Options{Validator: v, Lint: lintOpt}); err != nil {
+				return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+			}
+			return nil
+		},
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().StringVar(&schemaPath, "schema", "", "Path to a JSON Schema that overrides per-version selection")
+	cmd.Flags().StringVar(&extensionsDir, "extensions-dir", "", "Directory of <name>.json extension schemas (defaults to "+defaultExtensionsDir+" if present)")
+	cmd.Flags().StringVar(&unknownExtensions, "unknown-extensions", "warn", "How to report extensions without a schema: ignore|warn|error")
+	cmd.Flags().StringArrayVar(&rules, "rule", nil, "Override a lint rule's severity, e.g. system-type=error (repeatable)")
+	return cmd
+}
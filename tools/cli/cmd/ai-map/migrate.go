@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/migrate"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/textdiff"
+	"github.com/spf13/cobra"
+)
+
+func newMigrateCmd(stdout, stderr io.Writer) *cobra.Command {
+	var sel input.Selection
+	var to int
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate [--to VERSION] [--dry-run] [--dir DIR] [--recursive | --discover] [files... | - | REV:file]",
+		Short: "Upgrade maps to a newer spec version in place",
+		Long: "Rewrites AI-Map files to a target spec version (latest by default), preserving comments.\n" +
+			"With --dry-run, prints a unified diff instead of writing.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			inputs, err := input.SelectFiles(sel, args)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+			if err := input.EnsureSelected(sel, inputs); err != nil {
+				_ = cmd.Help()
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+			if cmd.Flags().Changed("to") && to <= 0 {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --to must be a positive version"}
+			}
+
+			for _, arg := range inputs {
+				if !dryRun && !input.IsFile(arg) {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: cannot migrate in place; use --dry-run", input.DisplayName(arg))}
+				}
+			}
+
+			var failed bool
+			for _, arg := range inputs {
+				p := input.DisplayName(arg)
+				b, err := input.Read(arg, input.MaxYAMLBytes)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", p, err)}
+				}
+				res, err := migrate.Migrate(b, to)
+				if err != nil {
+					failed = true
+					fmt.Fprintf(stderr, "%s: error: %s\n", p, err)
+					continue
+				}
+				if !res.Changed {
+					fmt.Fprintf(stderr, "%s: up to date (version %d)\n", p, res.To)
+					continue
+				}
+				if dryRun {
+					_, _ = stdout.Write(textdiff.Unified(p, p, b, res.Output))
+					continue
+				}
+				if err := writeFileAtomic(arg, res.Output); err != nil {
+					return cli.ExitError{Code: cli.ExitInternalError, Msg: fmt.Sprintf("%s: error: cannot write: %s", p, err)}
+				}
+				fmt.Fprintf(stderr, "%s: migrated %d -> %d (%s)\n", p, res.From, res.To, strings.Join(res.Steps, ", "))
+			}
+			if failed {
+				return cli.ExitError{Code: cli.ExitCheckFailed}
+			}
+			return nil
+		},
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().IntVar(&to, "to", 0, "Target spec version (defaults to the latest supported)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print diffs instead of writing files")
+	input.AddFlags(cmd.Flags(), &sel)
+	return cmd
+}
+
+// writeFileAtomic replaces path via a temp file in the same directory, keeping its mode.
+func writeFileAtomic(path string, data []byte) error {
+	mode := os.FileMode(0o644)
+	if st, err := os.Stat(path); err == nil {
+		mode = st.Mode().Perm()
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(mode); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
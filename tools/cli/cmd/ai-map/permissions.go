@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cjson"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/permissions"
+	"github.com/spf13/cobra"
+)
+
+func newPermissionsCmd(stdout, stderr io.Writer) *cobra.Command {
+	var mapPath string
+	var defaultDecision string
+	var format string
+
+	cmd := &cobra.Command{
+		Use:   "permissions [--map FILE] [--default write|read|ask|deny] [--format json|text] [paths...]",
+		Short: "Compute agent read/write/ask/deny decisions from a map",
+		Long: "Derives per-path agent permissions from boundaries.critical and extensions.ai-flow\n" +
+			"(ignore -> deny, safe_write -> write, critical -> ask; paths outside the map's directory -> read).\n" +
+			"The most specific matching rule decides; ignore, then safe_write, then critical breaks ties.\n" +
+			"Without paths, prints the rule set; with paths (relative to the working directory), also prints a decision for each.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			def, err := permissions.ParseDecision(defaultDecision)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --default: " + err.Error()}
+			}
+			absMap, err := filepath.Abs(mapPath)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: invalid --map: " + err.Error()}
+			}
+			b, err := input.ReadFileWithLimit(absMap, input.MaxYAMLBytes)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("%s: error: %s", mapPath, err)}
+			}
+			m, err := aimap.Parse(b)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitCheckFailed, Msg: fmt.Sprintf("%s: error: %s", mapPath, err)}
+			}
+
+			policy := permissions.FromMap(m, permissions.Options{Default: def})
+			mapDir := filepath.Dir(absMap)
+
+			var results []permissions.Result
+			if len(args) > 0 {
+				results = make([]permissions.Result, 0, len(args))
+			}
+			for _, a := range args {
+				abs, err := filepath.Abs(a)
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: fmt.Sprintf("error: invalid path %q: %s", a, err)}
+				}
+				rel, inside := aimap.RelTo(mapDir, abs)
+				results = append(results, policy.Decide(rel, inside))
+			}
+
+			switch strings.ToLower(format) {
+			case "json":
+				out, err := cjson.MarshalIndent(permissions.Export(mapPath, policy, results), "", "  ")
+				if err != nil {
+					return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+				}
+				_, _ = stdout.Write(out)
+			case "text":
+				if results == nil {
+					fmt.Fprintf(stdout, "default: %s\noutside: %s\n", policy.Default, policy.Outside)
+					for _, r := range policy.Rules {
+						fmt.Fprintf(stdout, "%-5s  %s  (%s)\n", r.Decision, r.Pattern, r.Source)
+					}
+					return nil
+				}
+				for _, r := range results {
+					if r.Rule != nil {
+						fmt.Fprintf(stdout, "%-5s  %s  (%s: %s)\n", r.Decision, r.Path, r.Rule.Source, r.Rule.Pattern)
+					} else {
+						fmt.Fprintf(stdout, "%-5s  %s\n", r.Decision, r.Path)
+					}
+				}
+			default:
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: unsupported --format (expected json|text)"}
+			}
+			return nil
+		},
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().StringVar(&mapPath, "map", ".ai-map.yaml", "Map file whose rules apply")
+	cmd.Flags().StringVar(&defaultDecision, "default", string(permissions.Write), "Decision for paths no rule covers")
+	cmd.Flags().StringVar(&format, "format", "json", "Output format: json|text")
+	return cmd
+}
@@ -8,6 +8,7 @@ import (
 	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
 	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
 	"github.com/olddognewflex/ai-map/tools/cli/internal/render"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/workpool"
 	"github.com/spf13/cobra"
 )
 
@@ -15,9 +16,10 @@ func newRenderCmd(stdout, stderr io.Writer) *cobra.Command {
 	var sel input.Selection
 	var outPath string
 	var title string
+	var jobs int
 
 	cmd := &cobra.Command{
-		Use:   "render [--out FILE] [--title TITLE] [--dir DIR] [--recursive] [files...]",
+		Use:   "render [--out FILE] [--title TITLE] [--jobs N] [--dir DIR] [--recursive | --discover] [files... | - | REV:file]",
 		Short: "Render AI-Map docs (Markdown)",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			inputs, err := input.SelectFiles(sel, args)
@@ -28,6 +30,9 @@ func newRenderCmd(stdout, stderr io.Writer) *cobra.Command {
 				_ = cmd.Help()
 				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
 			}
+			if err := checkJobs(jobs); err != nil {
+				return err
+			}
 
 			if outPath != "" && len(inputs) != 1 {
 				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --out requires exactly one input file"}
@@ -35,19 +40,27 @@ func newRenderCmd(stdout, stderr io.Writer) *cobra.Command {
 
 			outBytes, err := func() ([]byte, error) {
 				if len(inputs) == 1 {
-					b, err := input.ReadFileWithLimit(inputs[0], input.MaxYAMLBytes)
+					b, err := input.Read(inputs[0], input.MaxYAMLBytes)
 					if err != nil {
 						return nil, err
 					}
 					return render.MarkdownFromYAML(b, render.Options{Title: title})
 				}
-				var all []byte
-				for i, p := range inputs {
-					b, err := input.ReadFileWithLimit(p, input.MaxYAMLBytes)
+				type outcome struct {
+					md  []byte
+					err error
+				}
+				outcomes := workpool.Map(jobs, inputs, func(p string) outcome {
+					b, err := input.Read(p, input.MaxYAMLBytes)
 					if err != nil {
-						return nil, err
+						return outcome{err: err}
 					}
-					md, err := render.MarkdownFromYAML(b, render.Options{Title: "AI-Map: " + filepath.Base(p)})
+					md, err := render.MarkdownFromYAML(b, render.Options{Title: "AI-Map: " + filepath.Base(input.DisplayName(p))})
+					return outcome{md, err}
+				})
+				var all []byte
+				for i, o := range outcomes {
+					md, err := o.md, o.err
 					if err != nil {
 						return nil, err
 					}
@@ -90,8 +103,8 @@ func newRenderCmd(stdout, stderr io.Writer) *cobra.Command {
 	cmd.SetErr(stderr)
 	cmd.Flags().StringVar(&outPath, "out", "", "Output file (defaults to stdout)")
 	cmd.Flags().StringVar(&title, "title", "", "Document title (optional)")
-	cmd.Flags().StringVar(&sel.Dir, "dir", "", "Directory to scan for *.yml|*.yaml (non-recursive by default)")
-	cmd.Flags().BoolVar(&sel.Recursive, "recursive", false, "Scan directories recursively (off by default)")
+	input.AddFlags(cmd.Flags(), &sel)
+	addJobsFlag(cmd.Flags(), &jobs)
 	return cmd
 }
 
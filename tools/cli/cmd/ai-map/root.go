@@ -6,10 +6,15 @@ import (
 	"io"
 
 	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/config"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
 	"github.com/spf13/cobra"
 )
 
 func newRootCmd(stdout, stderr io.Writer) *cobra.Command {
+	var configPath string
+	var maxBytes int64
+
 	root := &cobra.Command{
 		Use:   "ai-map",
 		Short: "Tooling for the AI-Map spec",
@@ -18,9 +23,19 @@ func newRootCmd(stdout, stderr io.Writer) *cobra.Command {
 			_ = cmd.Help()
 			return cli.ExitError{Code: cli.ExitUsageOrConfig}
 		},
+		// Tool config defaults apply to whichever subcommand runs.
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadToolConfig(configPath)
+			if err != nil {
+				return err
+			}
+			return applyToolConfig(cmd, args, cfg, maxBytes)
+		},
 		SilenceErrors: true,
 		SilenceUsage:  true,
 	}
+	root.PersistentFlags().StringVar(&configPath, "config", "", "Tool config file (defaults to the nearest "+config.FileName+" above the working directory)")
+	root.PersistentFlags().Int64Var(&maxBytes, "max-bytes", input.DefaultMaxYAMLBytes, "Size cap for each YAML input")
 	// Keep the command surface area minimal and stable.
 	root.CompletionOptions.DisableDefaultCmd = true
 	root.SetOut(stdout)
@@ -42,6 +57,23 @@ func newRootCmd(stdout, stderr io.Writer) *cobra.Command {
 	root.AddCommand(newTypesCmd(stdout, stderr))
 	root.AddCommand(newConformanceCmd(stdout, stderr))
 	root.AddCommand(newScaffoldCmd(stdout, stderr))
+	root.AddCommand(newDiffCmd(stdout, stderr))
+	root.AddCommand(newMigrateCmd(stdout, stderr))
+	root.AddCommand(newPermissionsCmd(stdout, stderr))
+	root.AddCommand(newGuardCmd(stdout, stderr))
+	root.AddCommand(newCodeownersCmd(stdout, stderr))
+	root.AddCommand(newEffectiveCmd(stdout, stderr))
+	root.AddCommand(newCacheCmd(stdout, stderr))
+	root.AddCommand(newConfigCmd(stdout, stderr))
+	root.AddCommand(newWatchCmd(stdout, stderr))
+	root.AddCommand(newLSPCmd(stdout, stderr))
+	root.AddCommand(newServeCmd(stdout, stderr))
+	root.AddCommand(newExportCmd(stdout, stderr))
+	root.AddCommand(newImportCmd(stdout, stderr))
+	root.AddCommand(newDetectCmd(stdout, stderr))
+	root.AddCommand(newDriftCmd(stdout, stderr))
+	root.AddCommand(newCoverageCmd(stdout, stderr))
+	root.AddCommand(newContextCmd(stdout, stderr))
 	root.AddCommand(newVersionCmd(stdout, stderr))
 
 	return root
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/catalog"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/discover"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+func newServeCmd(stdout, stderr io.Writer) *cobra.Command {
+	var dir string
+	var addr string
+	var mapName string
+	var include, exclude []string
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "serve [--dir DIR] [--addr HOST:PORT] [--interval D]",
+		Short: "Serve a read-only JSON catalog of the maps under a directory",
+		Long: "Indexes the maps discovered under --dir (honouring .gitignore) and serves them over HTTP:\n\n" +
+			"  GET /v1/systems                    list systems\n" +
+			"  GET /v1/systems/{name}             one system with its effective (inherited) map\n" +
+			"  GET /v1/systems/{name}/dependents  systems listing it in dependencies.internal\n" +
+			"  GET /v1/owner?path=P               the system and ownership governing path P\n\n" +
+			"Responses are canonical JSON with content-hash ETags (If-None-Match is honoured). Map files\n" +
+			"are polled every --interval and the index is reloaded when they change. Stop with Ctrl-C.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --interval must be > 0"}
+			}
+			root, err := filepath.Abs(dir)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: invalid --dir: " + err.Error()}
+			}
+			var discoverOpt discover.Options
+			if mapName != "" {
+				discoverOpt.Names = []string{mapName}
+			}
+			discoverOpt.Include, discoverOpt.Exclude = include, exclude
+			idx, err := catalog.Load(root, discoverOpt)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+			for _, e := range idx.Errors {
+				fmt.Fprintf(stderr, "%s: error: skipped\n", e)
+			}
+
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+			srv := catalog.NewServer(idx)
+			httpSrv := &http.Server{Handler: srv, ReadHeaderTimeout: 10 * time.Second}
+			fmt.Fprintf(stderr, "serve: %d system(s) from %s on http://%s\n", len(idx.Systems), root, ln.Addr())
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			go func() {
+				targets := func() []string {
+					found, _ := discover.Find(root, discoverOpt)
+					return found
+				}
+				_ = watch.Loop(ctx, watch.Options{Interval: interval}, targets, func([]string) {
+					next, err := catalog.Load(root, discoverOpt)
+					if err != nil {
+						fmt.Fprintf(stderr, "serve: error: reload: %s\n", err)
+						return
+					}
+					srv.Set(next)
+					for _, e := range next.Errors {
+						fmt.Fprintf(stderr, "%s: error: skipped\n", e)
+					}
+					fmt.Fprintf(stderr, "[%s] serve: reloaded %d system(s)\n", time.Now().Format("15:04:05"), len(next.Systems))
+				})
+			}()
+			go func() {
+				<-ctx.Done()
+				shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+				defer cancel()
+				httpSrv.Shutdown(shutdownCtx)
+			}()
+
+			if err := httpSrv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+			}
+			fmt.Fprintln(stderr, "serve: stopped")
+			return nil
+		},
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().StringVar(&dir, "dir", ".", "Directory to discover maps under")
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:8080", "Address to listen on")
+	cmd.Flags().StringVar(&mapName, "map-name", discover.DefaultName, "Map file name to discover")
+	cmd.Flags().StringArrayVar(&include, "include", nil, "Only maps matching this glob (repeatable)")
+	cmd.Flags().StringArrayVar(&exclude, "exclude", nil, "Skip paths matching this glob (repeatable)")
+	cmd.Flags().DurationVar(&interval, "interval", time.Second, "How often to poll map files for changes")
+	return cmd
+}
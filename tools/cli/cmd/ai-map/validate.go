@@ -3,21 +3,30 @@ package main
 import (
 	"fmt"
 	"io"
+	"os"
 	"path/filepath"
 
 	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
 	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
 	"github.com/olddognewflex/ai-map/tools/cli/internal/validate"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/workpool"
 	"github.com/spf13/cobra"
 )
 
 func newValidateCmd(stdout, stderr io.Writer) *cobra.Command {
 	var sel input.Selection
 	var schemaPath string
+	var extensionsDir string
+	var unknownExtensions string
+	var jobs int
+	var cf cacheFlags
 
 	cmd := &cobra.Command{
-		Use:   "validate [--dir DIR] [--recursive] [files...]",
+		Use:   "validate [--schema FILE] [--extensions-dir DIR] [--jobs N] [--cache | --no-cache] [--dir DIR] [--recursive | --discover] [files... | - | REV:file]",
 		Short: "Validate YAML files against the JSON Schema",
+		Long: "Validates AI-Map files against the JSON Schema for the spec version each file declares.\n" +
+			"Schemas for supported versions are embedded; --schema forces one schema for every input.\n" +
+			"Each extensions.<name> block is checked against <extensions-dir>/<name>.json (built-in: ai-flow).",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			inputs, err := input.SelectFiles(sel, args)
 			if err != nil {
@@ -27,25 +36,53 @@ func newValidateCmd(stdout, stderr io.Writer) *cobra.Command {
 				_ = cmd.Help()
 				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
 			}
+			if err := checkJobs(jobs); err != nil {
+				return err
+			}
 
-			v, err := validate.New(validate.Options{
-				MaxBytes:    input.MaxYAMLBytes,
-				SchemaPath:  schemaPath,
-			})
+			v, err := newValidator(schemaPath, extensionsDir, unknownExtensions)
 			if err != nil {
-				return cli.ExitError{
-					Code: cli.ExitUsageOrConfig,
-					Msg:  fmt.Sprintf("error: %s\nhint: if the repo does not yet include spec/ai-map.schema.json, provide --schema PATH (or approve adding a placeholder under spec/).", err),
-				}
+				return err
+			}
+			c, err := cf.open()
+			if err != nil {
+				return err
 			}
 
+			type outcome struct {
+				res validate.Result
+				err error
+			}
+			outcomes := workpool.Map(jobs, inputs, func(p string) outcome {
+				b, err := input.Read(p, input.MaxYAMLBytes)
+				if err != nil {
+					return outcome{err: err}
+				}
+				if c == nil {
+					return outcome{res: v.ValidateBytes(b)}
+				}
+				key := cacheKey(b, v.Fingerprint())
+				var res validate.Result
+				if c.Get("validate", key, &res) {
+					return outcome{res: res}
+				}
+				res = v.ValidateBytes(b)
+				// A failed write only costs a future cache miss.
+				_ = c.Put("validate", key, res)
+				return outcome{res: res}
+			})
+
 			var failed bool
-			for _, p := range inputs {
-				res, err := v.ValidateFile(p)
+			for i, arg := range inputs {
+				p := input.DisplayName(arg)
+				res, err := outcomes[i].res, outcomes[i].err
 				if err != nil {
 					fmt.Fprintf(stderr, "%s: error: %s\n", p, err)
 					return cli.ExitError{Code: cli.ExitInternalError}
 				}
+				for _, w := range res.Warnings {
+					fmt.Fprintf(stderr, "%s: warning: %s\n", p, cli.TrimTrailingNewline(w))
+				}
 				if res.OK {
 					continue
 				}
@@ -65,10 +102,36 @@ func newValidateCmd(stdout, stderr io.Writer) *cobra.Command {
 	cmd.SetOut(stdout)
 	cmd.SetErr(stderr)
 
-	cmd.Flags().StringVar(&schemaPath, "schema", filepath.Join("spec", "ai-map.schema.json"), "Path to JSON Schema")
-	cmd.Flags().StringVar(&sel.Dir, "dir", "", "Directory to scan for *.yml|*.yaml (non-recursive by default)")
-	cmd.Flags().BoolVar(&sel.Recursive, "recursive", false, "Scan directories recursively (off by default)")
+	cmd.Flags().StringVar(&schemaPath, "schema", "", "Path to a JSON Schema that overrides per-version selection")
+	cmd.Flags().StringVar(&extensionsDir, "extensions-dir", "", "Directory of <name>.json extension schemas (defaults to "+defaultExtensionsDir+" if present)")
+	cmd.Flags().StringVar(&unknownExtensions, "unknown-extensions", "warn", "How to report extensions without a schema: ignore|warn|error")
+	input.AddFlags(cmd.Flags(), &sel)
+	addJobsFlag(cmd.Flags(), &jobs)
+	addCacheFlags(cmd.Flags(), &cf)
 	return cmd
 }
 
+// newValidator builds the validator shared by validate, watch and lsp.
+func newValidator(schemaPath, extensionsDir, unknownExtensions string) (*validate.Validator, error) {
+	if extensionsDir == "" && dirExists(defaultExtensionsDir) {
+		extensionsDir = defaultExtensionsDir
+	}
+	v, err := validate.New(validate.Options{
+		MaxBytes:          input.MaxYAMLBytes,
+		SchemaPath:        schemaPath,
+		ExtensionsDir:     extensionsDir,
+		UnknownExtensions: validate.UnknownExtensions(unknownExtensions),
+	})
+	if err != nil {
+		return nil, cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+	}
+	return v, nil
+}
 
+// defaultExtensionsDir is where teams keep extension schemas, relative to the working directory.
+var defaultExtensionsDir = filepath.Join("schemas", "extensions")
+
+func dirExists(p string) bool {
+	st, err := os.Stat(p)
+	return err == nil && st.IsDir()
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cli"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/discover"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/lint"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/validate"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/watch"
+	"github.com/spf13/cobra"
+)
+
+func newWatchCmd(stdout, stderr io.Writer) *cobra.Command {
+	var dir string
+	var mapName string
+	var include, exclude []string
+	var schemaPath string
+	var extensionsDir string
+	var unknownExtensions string
+	var rules []string
+	var interval, debounce time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "watch [--dir DIR] [--interval D] [--debounce D]",
+		Short: "Re-validate and re-lint maps as they and the paths they reference change",
+		Long: "Discovers map files under --dir (honouring .gitignore, like --discover) and polls them and\n" +
+			"every path they reference (entrypoints, models, critical and config paths, docs). When\n" +
+			"something changes, only the affected maps are re-validated and re-linted, followed by a\n" +
+			"one-line status. Stop with Ctrl-C.",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 || debounce < 0 {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: --interval must be > 0 and --debounce >= 0"}
+			}
+			root, err := filepath.Abs(dir)
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: invalid --dir: " + err.Error()}
+			}
+			v, err := newValidator(schemaPath, extensionsDir, unknownExtensions)
+			if err != nil {
+				return err
+			}
+			lintOpt, err := lintOptions(rules)
+			if err != nil {
+				return err
+			}
+			discoverOpt := discover.Options{Names: []string{mapName}, Include: include, Exclude: exclude}
+			if _, err := discover.Find(root, discoverOpt); err != nil {
+				return cli.ExitError{Code: cli.ExitUsageOrConfig, Msg: "error: " + err.Error()}
+			}
+
+			w := &mapWatcher{
+				root:     root,
+				stdout:   stdout,
+				validate: v,
+				lint:     lintOpt,
+				discover: func() []string {
+					found, _ := discover.Find(root, discoverOpt)
+					return found
+				},
+				maps: map[string]*watchedMap{},
+			}
+			w.refresh(nil)
+
+			ctx, stop := signal.NotifyContext(cmd.Context(), os.Interrupt, syscall.SIGTERM)
+			defer stop()
+			err = watch.Loop(ctx, watch.Options{Interval: interval, Debounce: debounce}, w.targets, w.refresh)
+			fmt.Fprintln(stderr, "watch: stopped")
+			if err != nil {
+				return cli.ExitError{Code: cli.ExitInternalError, Msg: "error: " + err.Error()}
+			}
+			return nil
+		},
+	}
+
+	cmd.SetOut(stdout)
+	cmd.SetErr(stderr)
+	cmd.Flags().StringVar(&dir, "dir", ".", "Directory to discover maps under")
+	cmd.Flags().StringVar(&mapName, "map-name", discover.DefaultName, "Map file name to discover")
+	cmd.Flags().StringArrayVar(&include, "include", nil, "Only maps matching this glob (repeatable)")
+	cmd.Flags().StringArrayVar(&exclude, "exclude", nil, "Skip paths matching this glob (repeatable)")
+	cmd.Flags().StringVar(&schemaPath, "schema", "", "Path to a JSON Schema that overrides per-version selection")
+	cmd.Flags().StringVar(&extensionsDir, "extensions-dir", "", "Directory of <name>.json extension schemas (defaults to "+defaultExtensionsDir+" if present)")
+	cmd.Flags().StringVar(&unknownExtensions, "unknown-extensions", "warn", "How to report extensions without a schema: ignore|warn|error")
+	cmd.Flags().StringArrayVar(&rules, "rule", nil, "Override a lint rule's severity, e.g. system-type=error (repeatable)")
+	cmd.Flags().DurationVar(&interval, "interval", 500*time.Millisecond, "How often to poll for changes")
+	cmd.Flags().DurationVar(&debounce, "debounce", 300*time.Millisecond, "Quiet period before re-checking after a change")
+	return cmd
+}
+
+type watchedMap struct {
+	// refs are absolute paths (static prefixes of patterns) the map refers to.
+	refs   []string
+	diags  []string
+	failed bool
+}
+
+type mapWatcher struct {
+	root     string
+	stdout   io.Writer
+	validate *validate.Validator
+	lint     lint.Options
+	discover func() []string
+	maps     map[string]*watchedMap
+}
+
+// targets is every discovered map plus everything the known maps reference.
+func (w *mapWatcher) targets() []string {
+	out := w.discover()
+	for _, m := range w.maps {
+		out = append(out, m.refs...)
+	}
+	return out
+}
+
+// refresh re-checks the maps affected by changed (all maps when changed is nil)
+// and prints their diagnostics followed by a status line.
+func (w *mapWatcher) refresh(changed []string) {
+	current := map[string]bool{}
+	for _, p := range w.discover() {
+		current[p] = true
+	}
+
+	affected := map[string]bool{}
+	for p := range current {
+		if _, known := w.maps[p]; changed == nil || !known {
+			affected[p] = true
+		}
+	}
+	for p := range w.maps {
+		if !current[p] {
+			affected[p] = true
+		}
+	}
+	for _, c := range changed {
+		if _, ok := w.maps[c]; ok {
+			affected[c] = true
+		}
+		for p, m := range w.maps {
+			for _, r := range m.refs {
+				if c == r || strings.HasPrefix(c, r+string(filepath.Separator)) {
+					affected[p] = true
+				}
+			}
+		}
+	}
+
+	paths := make([]string, 0, len(affected))
+	for p := range affected {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	for _, p := range paths {
+		if !current[p] {
+			delete(w.maps, p)
+			fmt.Fprintf(w.stdout, "%s: removed\n", w.name(p))
+			continue
+		}
+		m := w.check(p)
+		w.maps[p] = m
+		for _, d := range m.diags {
+			fmt.Fprintln(w.stdout, d)
+		}
+	}
+
+	failing := 0
+	for _, m := range w.maps {
+		if m.failed {
+			failing++
+		}
+	}
+	fmt.Fprintf(w.stdout, "[%s] %d map(s): %d ok, %d failing (checked %d)\n",
+		time.Now().Format("15:04:05"), len(w.maps), len(w.maps)-failing, failing, len(paths))
+}
+
+// check validates and lints one map, formatting diagnostics like validate and lint.
+func (w *mapWatcher) check(p string) *watchedMap {
+	name := w.name(p)
+	m := &watchedMap{}
+	b, err := input.Read(p, input.MaxYAMLBytes)
+	if err != nil {
+		m.failed = true
+		m.diags = append(m.diags, fmt.Sprintf("%s: error: %s", name, err))
+		return m
+	}
+
+	res := w.validate.ValidateBytes(b)
+	for _, warn := range res.Warnings {
+		m.diags = append(m.diags, fmt.Sprintf("%s: warning: %s", name, cli.TrimTrailingNewline(warn)))
+	}
+	if !res.OK {
+		m.failed = true
+		m.diags = append(m.diags, name+": invalid")
+		for _, e := range res.Errors {
+			m.diags = append(m.diags, "  - "+cli.TrimTrailingNewline(e))
+		}
+	}
+	lres := lint.LintYAMLBytes(b)
+	lres.Issues = append(lres.Issues, lint.LintFile(p, b, w.lint).Issues...)
+	for _, is := range w.lint.Apply(lres).Issues {
+		if is.Path != "" {
+			m.diags = append(m.diags, fmt.Sprintf("%s: %s: %s (%s)", name, is.Severity, is.Message, is.Path))
+		} else {
+			m.diags = append(m.diags, fmt.Sprintf("%s: %s: %s", name, is.Severity, is.Message))
+		}
+		if is.Severity == lint.SeverityError {
+			m.failed = true
+		}
+	}
+
+	if parsed, err := aimap.Parse(b); err == nil {
+		dir := filepath.Dir(p)
+		for _, ref := range parsed.LocalPaths() {
+			m.refs = append(m.refs, filepath.Join(dir, filepath.FromSlash(aimap.StaticPrefix(ref))))
+		}
+	}
+	return m
+}
+
+func (w *mapWatcher) name(p string) string {
+	if rel, err := filepath.Rel(w.root, p); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return p
+}
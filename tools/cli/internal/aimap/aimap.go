@@ -0,0 +1,91 @@
+// Package aimap decodes AI-Map documents into the JSON-compatible shape the
+// rest of the tooling works on (map[string]any, []any and scalars).
+package aimap
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Decode parses YAML bytes and normalizes them into JSON-ready values.
+// The top-level document must be a mapping; an empty document decodes to an empty map.
+func Decode(b []byte) (map[string]any, error) {
+	var doc any
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("YAML parse error: %w", err)
+	}
+	if doc == nil {
+		return map[string]any{}, nil
+	}
+	v, err := Normalize(doc)
+	if err != nil {
+		return nil, fmt.Errorf("YAML normalization error: %w", err)
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil, errors.New("top-level document must be a mapping/object")
+	}
+	return m, nil
+}
+
+// Normalize converts yaml.v3 output into JSON-compatible values with string map keys.
+func Normalize(v any) (any, error) {
+	switch x := v.(type) {
+	case nil, bool, string:
+		return x, nil
+	case int, int8, int16, int32, int64:
+		return x, nil
+	case uint, uint8, uint16, uint32, uint64:
+		return x, nil
+	case float32, float64:
+		return x, nil
+	case []any:
+		out := make([]any, 0, len(x))
+		for _, it := range x {
+			cv, err := Normalize(it)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, cv)
+		}
+		return out, nil
+	case map[string]any:
+		out := make(map[string]any, len(x))
+		for k, v := range x {
+			cv, err := Normalize(v)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = cv
+		}
+		return out, nil
+	case map[any]any:
+		out := make(map[string]any, len(x))
+		for k, v := range x {
+			ks, ok := k.(string)
+			if !ok {
+				return nil, fmt.Errorf("non-string map key %T", k)
+			}
+			cv, err := Normalize(v)
+			if err != nil {
+				return nil, err
+			}
+			out[ks] = cv
+		}
+		return out, nil
+	default:
+		// Fallback: attempt JSON roundtrip for scalar-ish types (e.g. time.Time) but keep it deterministic.
+		b, err := json.Marshal(x)
+		if err != nil {
+			return nil, fmt.Errorf("unsupported YAML value %T", x)
+		}
+		var out any
+		if err := json.Unmarshal(b, &out); err != nil {
+			return nil, fmt.Errorf("unsupported YAML value %T", x)
+		}
+		return out, nil
+	}
+}
@@ -0,0 +1,89 @@
+package aimap
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/discover"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/git"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+)
+
+// FileName is the spec-defined name of a map file (spec section 1).
+const FileName = discover.DefaultName
+
+// FindMaps returns the absolute paths of every map file under root, sorted.
+// .gitignore'd and dependency directories are skipped.
+func FindMaps(root string) ([]string, error) {
+	return discover.Find(root, discover.Options{Names: []string{FileName}})
+}
+
+// Located is a parsed map and where it lives relative to the repository root.
+type Located struct {
+	// Path is the map file, relative to the root.
+	Path string
+	// Dir is the directory the map governs, relative to the root ("." for the root map).
+	Dir string
+	Map *Map
+}
+
+// LoadTree finds and parses every map under root.
+func LoadTree(root string, maxBytes int64) ([]Located, error) {
+	paths, err := FindMaps(root)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Located, 0, len(paths))
+	for _, p := range paths {
+		b, err := input.ReadFileWithLimit(p, maxBytes)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		m, err := Parse(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", p, err)
+		}
+		rel, _ := RelTo(root, p)
+		dir, _ := RelTo(root, filepath.Dir(p))
+		out = append(out, Located{Path: rel, Dir: dir, Map: m})
+	}
+	return out, nil
+}
+
+// LoadTreeAt finds and parses every map committed in rev of the repository
+// whose work tree root is root. Paths are relative to root, as in LoadTree.
+func LoadTreeAt(root, rev string, maxBytes int64) ([]Located, error) {
+	files, err := git.TrackedFiles(root, rev)
+	if err != nil {
+		return nil, err
+	}
+	var out []Located
+	for _, f := range files {
+		if path.Base(f) != FileName || skipped(f) {
+			continue
+		}
+		b, err := git.Show(rev, filepath.Join(root, filepath.FromSlash(f)), maxBytes)
+		if err != nil {
+			return nil, err
+		}
+		m, err := Parse(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%s: %w", rev, f, err)
+		}
+		out = append(out, Located{Path: f, Dir: path.Dir(f), Map: m})
+	}
+	return out, nil
+}
+
+// skipped reports whether a repo-relative path lies under a directory that
+// discovery never descends into.
+func skipped(rel string) bool {
+	for _, seg := range strings.Split(path.Dir(rel), "/") {
+		if discover.SkipDirs[seg] {
+			return true
+		}
+	}
+	return false
+}
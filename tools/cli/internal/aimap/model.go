@@ -0,0 +1,145 @@
+package aimap
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Map is the typed v1 model used by commands that interpret a map rather than
+// just validate it. Unknown keys are ignored; use Decode for the raw document.
+type Map struct {
+	Version      int            `yaml:"version"`
+	System       System         `yaml:"system"`
+	Boundaries   Boundaries     `yaml:"boundaries"`
+	Dependencies Dependencies   `yaml:"dependencies"`
+	Ownership    Ownership      `yaml:"ownership"`
+	Runtime      Runtime        `yaml:"runtime"`
+	Extensions   map[string]any `yaml:"extensions"`
+}
+
+type System struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	Domain   string `yaml:"domain"`
+	Language string `yaml:"language"`
+}
+
+type Boundaries struct {
+	Entrypoints map[string][]string `yaml:"entrypoints"`
+	Models      []string            `yaml:"models"`
+	Critical    []string            `yaml:"critical"`
+}
+
+type Dependencies struct {
+	Internal []string `yaml:"internal"`
+	External []string `yaml:"external"`
+}
+
+type Ownership struct {
+	Team  string `yaml:"team"`
+	Slack string `yaml:"slack"`
+	Docs  Docs   `yaml:"docs"`
+}
+
+type Docs struct {
+	ADR     string `yaml:"adr"`
+	Runbook string `yaml:"runbook"`
+	// OpenAPI is the service's API description, as recorded by `import --from openapi`.
+	OpenAPI string `yaml:"openapi"`
+}
+
+type Runtime struct {
+	Environment string   `yaml:"environment"`
+	DeploysVia  string   `yaml:"deploys_via"`
+	ConfigPaths []string `yaml:"config_paths"`
+}
+
+// AIFlow is the `extensions.ai-flow` block from spec section 5.
+type AIFlow struct {
+	Ignore    []string
+	SafeWrite []string
+}
+
+// Parse decodes YAML bytes into the typed model.
+func Parse(b []byte) (*Map, error) {
+	var m Map
+	if err := yaml.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("YAML parse error: %w", err)
+	}
+	return &m, nil
+}
+
+// Protocols returns entrypoint protocol names in sorted order.
+func (b Boundaries) Protocols() []string {
+	out := make([]string, 0, len(b.Entrypoints))
+	for p := range b.Entrypoints {
+		out = append(out, p)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// AllEntrypoints returns every entrypoint path across protocols, in protocol order.
+func (b Boundaries) AllEntrypoints() []string {
+	var out []string
+	for _, p := range b.Protocols() {
+		out = append(out, b.Entrypoints[p]...)
+	}
+	return out
+}
+
+// LocalPaths returns the repository paths the map refers to (entrypoints, models,
+// critical paths, config paths and docs), cleaned, de-duplicated and sorted. URLs
+// and absolute paths are left out; glob patterns are kept as written.
+func (m *Map) LocalPaths() []string {
+	var all []string
+	all = append(all, m.Boundaries.AllEntrypoints()...)
+	all = append(all, m.Boundaries.Models...)
+	all = append(all, m.Boundaries.Critical...)
+	all = append(all, m.Runtime.ConfigPaths...)
+	all = append(all, m.Ownership.Docs.ADR, m.Ownership.Docs.Runbook, m.Ownership.Docs.OpenAPI)
+
+	seen := map[string]bool{}
+	var out []string
+	for _, p := range all {
+		p = strings.TrimSpace(p)
+		if p == "" || strings.Contains(p, "://") || strings.HasPrefix(p, "/") {
+			continue
+		}
+		if c := CleanPath(p); !seen[c] {
+			seen[c] = true
+			out = append(out, c)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// AIFlow returns the ai-flow extension, or a zero value when absent or malformed.
+func (m *Map) AIFlow() AIFlow {
+	raw, ok := m.Extensions["ai-flow"].(map[string]any)
+	if !ok {
+		return AIFlow{}
+	}
+	return AIFlow{
+		Ignore:    stringList(raw["ignore"]),
+		SafeWrite: stringList(raw["safe_write"]),
+	}
+}
+
+func stringList(v any) []string {
+	l, ok := v.([]any)
+	if !ok {
+		return nil
+	}
+	var out []string
+	for _, it := range l {
+		if s, ok := it.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
@@ -0,0 +1,90 @@
+package aimap
+
+import (
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// CleanPath normalizes a boundary or file path to a slash-separated, repo-relative
+// form without a leading "./" or trailing "/". The map root itself is ".".
+func CleanPath(p string) string {
+	p = path.Clean(filepath.ToSlash(strings.TrimSpace(p)))
+	p = strings.TrimPrefix(p, "/")
+	if p == "" {
+		return "."
+	}
+	return p
+}
+
+// MatchPath reports whether rel (relative to the map's directory) falls under the
+// boundary pattern. A plain pattern covers itself and everything beneath it; a pattern
+// with glob characters (*, ?, [ ]) covers whatever it matches and, when it matches a
+// directory prefix, that directory's subtree. "**" matches any number of segments.
+func MatchPath(pattern, rel string) bool {
+	pattern, rel = CleanPath(pattern), CleanPath(rel)
+	if pattern == "." {
+		return true
+	}
+	if !hasGlob(pattern) {
+		return rel == pattern || strings.HasPrefix(rel, pattern+"/")
+	}
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(rel, "/"))
+}
+
+// StaticPrefix returns the leading segments of a path pattern before the first
+// glob, i.e. the directory a watcher or scanner has to look at ("." if none).
+func StaticPrefix(pattern string) string {
+	var segs []string
+	for _, s := range strings.Split(CleanPath(pattern), "/") {
+		if hasGlob(s) {
+			break
+		}
+		segs = append(segs, s)
+	}
+	if len(segs) == 0 {
+		return "."
+	}
+	return strings.Join(segs, "/")
+}
+
+func hasGlob(s string) bool {
+	return strings.ContainsAny(s, "*?[")
+}
+
+// matchSegments matches pattern segments against a prefix of path segments.
+func matchSegments(pat, segs []string) bool {
+	if len(pat) == 0 {
+		// Pattern consumed: it matched this path or one of its ancestors.
+		return true
+	}
+	if pat[0] == "**" {
+		for i := 0; i <= len(segs); i++ {
+			if matchSegments(pat[1:], segs[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segs) == 0 {
+		return false
+	}
+	ok, err := path.Match(pat[0], segs[0])
+	if err != nil || !ok {
+		return false
+	}
+	return matchSegments(pat[1:], segs[1:])
+}
+
+// RelTo returns target relative to root as a slash path, and whether it lies inside root.
+func RelTo(root, target string) (string, bool) {
+	rel, err := filepath.Rel(root, target)
+	if err != nil {
+		return "", false
+	}
+	rel = filepath.ToSlash(rel)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		return rel, false
+	}
+	return CleanPath(rel), true
+}
@@ -0,0 +1,266 @@
+// Package backstage converts between AI-Map documents and Backstage
+// catalog-info.yaml Component entities.
+//
+// Field mapping (both directions):
+//
+//	system.name             metadata.name
+//	system.type             spec.type (webapp <-> website)
+//	system.domain           spec.system
+//	ownership.team          spec.owner
+//	dependencies.internal   spec.dependsOn (component:<name>; other kinds are skipped on import)
+//	ownership.docs.adr      metadata.links, title "ADR"
+//	ownership.docs.runbook  metadata.links, title "Runbook"
+//
+// Export additionally writes system.language as a tag and spec.lifecycle, which
+// Backstage requires; neither is read back. Everything else is not carried over.
+package backstage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	APIVersion    = "backstage.io/v1alpha1"
+	KindComponent = "Component"
+
+	// DefaultLifecycle is used for spec.lifecycle when none is given.
+	DefaultLifecycle = "production"
+
+	titleADR     = "ADR"
+	titleRunbook = "Runbook"
+)
+
+// Entity is the subset of a Backstage catalog entity the conversion uses.
+type Entity struct {
+	APIVersion string   `yaml:"apiVersion"`
+	Kind       string   `yaml:"kind"`
+	Metadata   Metadata `yaml:"metadata"`
+	Spec       Spec     `yaml:"spec"`
+}
+
+type Metadata struct {
+	Name  string   `yaml:"name"`
+	Tags  []string `yaml:"tags,omitempty"`
+	Links []Link   `yaml:"links,omitempty"`
+}
+
+type Link struct {
+	URL   string `yaml:"url"`
+	Title string `yaml:"title,omitempty"`
+}
+
+type Spec struct {
+	Type      string   `yaml:"type,omitempty"`
+	Lifecycle string   `yaml:"lifecycle,omitempty"`
+	Owner     string   `yaml:"owner,omitempty"`
+	System    string   `yaml:"system,omitempty"`
+	DependsOn []string `yaml:"dependsOn,omitempty"`
+}
+
+// Options tunes both directions.
+type Options struct {
+	// Lifecycle is spec.lifecycle on export (DefaultLifecycle when empty).
+	Lifecycle string
+	// BaseURL, when set, is prefixed to repo-relative doc paths on export and
+	// stripped from link URLs on import.
+	BaseURL string
+}
+
+// typeToBackstage maps system.type values that Backstage names differently.
+var typeToBackstage = map[string]string{"webapp": "website"}
+
+// Export converts a map into a Component entity.
+func Export(m *aimap.Map, opt Options) Entity {
+	e := Entity{APIVersion: APIVersion, Kind: KindComponent}
+	e.Metadata.Name = m.System.Name
+	if m.System.Language != "" {
+		e.Metadata.Tags = []string{strings.ToLower(m.System.Language)}
+	}
+	for _, l := range []struct{ title, path string }{
+		{titleADR, m.Ownership.Docs.ADR},
+		{titleRunbook, m.Ownership.Docs.Runbook},
+	} {
+		if l.path != "" {
+			e.Metadata.Links = append(e.Metadata.Links, Link{URL: docURL(l.path, opt.BaseURL), Title: l.title})
+		}
+	}
+
+	e.Spec.Type = m.System.Type
+	if t, ok := typeToBackstage[m.System.Type]; ok {
+		e.Spec.Type = t
+	}
+	e.Spec.Lifecycle = opt.Lifecycle
+	if e.Spec.Lifecycle == "" {
+		e.Spec.Lifecycle = DefaultLifecycle
+	}
+	e.Spec.Owner = m.Ownership.Team
+	e.Spec.System = m.System.Domain
+	for _, d := range m.Dependencies.Internal {
+		e.Spec.DependsOn = append(e.Spec.DependsOn, "component:"+d)
+	}
+	return e
+}
+
+// Draft is the map Import produces: only the sections it can fill, in spec order.
+type Draft struct {
+	Version      int                `yaml:"version"`
+	System       DraftSystem        `yaml:"system"`
+	Dependencies *DraftDependencies `yaml:"dependencies,omitempty"`
+	Ownership    *DraftOwnership    `yaml:"ownership,omitempty"`
+}
+
+type DraftSystem struct {
+	Name   string `yaml:"name"`
+	Type   string `yaml:"type,omitempty"`
+	Domain string `yaml:"domain,omitempty"`
+}
+
+type DraftDependencies struct {
+	Internal []string `yaml:"internal"`
+}
+
+type DraftOwnership struct {
+	Team string     `yaml:"team,omitempty"`
+	Docs *DraftDocs `yaml:"docs,omitempty"`
+}
+
+type DraftDocs struct {
+	ADR     string `yaml:"adr,omitempty"`
+	Runbook string `yaml:"runbook,omitempty"`
+}
+
+// Import converts a Component entity into a draft map.
+func Import(e Entity, opt Options) Draft {
+	d := Draft{Version: 1}
+	d.System.Name = e.Metadata.Name
+	d.System.Type = e.Spec.Type
+	for ours, theirs := range typeToBackstage {
+		if e.Spec.Type == theirs {
+			d.System.Type = ours
+		}
+	}
+	d.System.Domain = entityName(e.Spec.System)
+
+	for _, dep := range e.Spec.DependsOn {
+		// Only components are systems; resources and APIs have no counterpart.
+		if kind, _, ok := strings.Cut(dep, ":"); ok && !strings.EqualFold(kind, "component") {
+			continue
+		}
+		if d.Dependencies == nil {
+			d.Dependencies = &DraftDependencies{}
+		}
+		d.Dependencies.Internal = append(d.Dependencies.Internal, entityName(dep))
+	}
+
+	var own DraftOwnership
+	own.Team = entityName(e.Spec.Owner)
+	var docs DraftDocs
+	for _, l := range e.Metadata.Links {
+		switch strings.ToLower(l.Title) {
+		case "adr", "adrs", "architecture decision records":
+			docs.ADR = docPath(l.URL, opt.BaseURL)
+		case "runbook":
+			docs.Runbook = docPath(l.URL, opt.BaseURL)
+		}
+	}
+	if docs != (DraftDocs{}) {
+		own.Docs = &docs
+	}
+	if own.Team != "" || own.Docs != nil {
+		d.Ownership = &own
+	}
+	return d
+}
+
+// ParseEntities decodes every document in a catalog-info.yaml file.
+func ParseEntities(b []byte) ([]Entity, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	var out []Entity
+	for {
+		var e Entity
+		err := dec.Decode(&e)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("YAML parse error: %w", err)
+		}
+		if e.Kind != "" {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// SelectComponent picks the Component named name, or the only Component when
+// name is empty.
+func SelectComponent(entities []Entity, name string) (Entity, error) {
+	var comps []Entity
+	for _, e := range entities {
+		if e.Kind == KindComponent && (name == "" || e.Metadata.Name == name) {
+			comps = append(comps, e)
+		}
+	}
+	switch {
+	case len(comps) == 1:
+		return comps[0], nil
+	case len(comps) == 0 && name != "":
+		return Entity{}, fmt.Errorf("no Component named %q", name)
+	case len(comps) == 0:
+		return Entity{}, errors.New("no Component entity found")
+	}
+	names := make([]string, 0, len(comps))
+	for _, c := range comps {
+		names = append(names, c.Metadata.Name)
+	}
+	return Entity{}, fmt.Errorf("several Components (%s); pick one with --name", strings.Join(names, ", "))
+}
+
+// Marshal encodes v as YAML with two-space indentation.
+func Marshal(v any) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// entityName strips the kind and namespace from an entity reference
+// ("group:default/platform" -> "platform").
+func entityName(ref string) string {
+	if i := strings.Index(ref, ":"); i >= 0 {
+		ref = ref[i+1:]
+	}
+	if i := strings.LastIndex(ref, "/"); i >= 0 {
+		ref = ref[i+1:]
+	}
+	return ref
+}
+
+func docURL(p, base string) string {
+	if base == "" || strings.Contains(p, "://") {
+		return p
+	}
+	return strings.TrimSuffix(base, "/") + "/" + aimap.CleanPath(p)
+}
+
+func docPath(url, base string) string {
+	if base != "" {
+		if rest, ok := strings.CutPrefix(url, strings.TrimSuffix(base, "/")+"/"); ok {
+			return rest
+		}
+	}
+	return url
+}
@@ -0,0 +1,136 @@
+package backstage
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+const sampleMap = `version: 1
+system:
+  name: checkout
+  type: webapp
+  domain: commerce
+  language: TypeScript
+dependencies:
+  internal: [payments, catalog]
+  external: [stripe]
+ownership:
+  team: web-platform
+  slack: "#web"
+  docs:
+    adr: docs/adr
+    runbook: docs/runbook.md
+`
+
+func TestMapRoundTrip(t *testing.T) {
+	m, err := aimap.Parse([]byte(sampleMap))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opt := Options{BaseURL: "https://git.example.com/shop/-/blob/main/"}
+	out, err := Marshal(Export(m, opt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	entities, err := ParseEntities(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := SelectComponent(entities, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Spec.Type != "website" || e.Spec.Lifecycle != DefaultLifecycle || e.Metadata.Links[0].URL != "https://git.example.com/shop/-/blob/main/docs/adr" {
+		t.Fatalf("unexpected entity:\n%s", out)
+	}
+
+	draft, err := Marshal(Import(e, opt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	back, err := aimap.Parse(draft)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := *m
+	// Fields without a clean Backstage counterpart do not survive the trip.
+	want.System.Language = ""
+	want.Dependencies.External = nil
+	want.Ownership.Slack = ""
+	if !reflect.DeepEqual(*back, want) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v\ndraft:\n%s", *back, want, draft)
+	}
+}
+
+const sampleEntity = `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: payments
+  links:
+    - url: docs/runbook.md
+      title: Runbook
+spec:
+  type: service
+  lifecycle: experimental
+  owner: payments-team
+  system: billing
+  dependsOn:
+    - component:ledger
+---
+apiVersion: backstage.io/v1alpha1
+kind: API
+metadata:
+  name: payments-api
+spec:
+  type: openapi
+`
+
+func TestEntityRoundTrip(t *testing.T) {
+	entities, err := ParseEntities([]byte(sampleEntity))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := SelectComponent(entities, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	draft, err := Marshal(Import(e, Options{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := aimap.Parse(draft)
+	if err != nil {
+		t.Fatal(err)
+	}
+	back := Export(m, Options{Lifecycle: "experimental"})
+	if !reflect.DeepEqual(back, e) {
+		t.Fatalf("round trip mismatch:\n got %+v\nwant %+v\ndraft:\n%s", back, e, draft)
+	}
+}
+
+func TestImportReferences(t *testing.T) {
+	e := Entity{Kind: KindComponent}
+	e.Metadata.Name = "api"
+	e.Spec.Owner = "group:default/platform"
+	e.Spec.System = "system:commerce"
+	e.Spec.DependsOn = []string{"component:default/users", "resource:orders-db", "billing"}
+	d := Import(e, Options{})
+	if d.Ownership.Team != "platform" || d.System.Domain != "commerce" {
+		t.Fatalf("references not stripped: %+v", d)
+	}
+	if want := []string{"users", "billing"}; !reflect.DeepEqual(d.Dependencies.Internal, want) {
+		t.Fatalf("dependsOn imported as %v, want %v", d.Dependencies.Internal, want)
+	}
+}
+
+func TestSelectComponent(t *testing.T) {
+	two := []Entity{{Kind: KindComponent, Metadata: Metadata{Name: "a"}}, {Kind: KindComponent, Metadata: Metadata{Name: "b"}}}
+	if _, err := SelectComponent(two, ""); err == nil {
+		t.Fatal("expected ambiguity error")
+	}
+	if e, err := SelectComponent(two, "b"); err != nil || e.Metadata.Name != "b" {
+		t.Fatalf("select by name: %v %v", e, err)
+	}
+}
@@ -0,0 +1,134 @@
+// Package cache stores per-file results on disk so repeated runs (e.g. pre-commit
+// hooks) can skip unchanged inputs. Entries are keyed by a hash of the file content
+// and everything else that affects the result; a changed schema, rule set or tool
+// version simply produces a different key.
+//
+// Entries are written to a temp file and renamed into place, so concurrent
+// processes sharing a cache directory never observe partial entries. Unreadable
+// or corrupt entries are treated as misses.
+package cache
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// EnvEnable turns the cache on when set to 1/true (same as --cache).
+	EnvEnable = "AI_MAP_CACHE"
+	// EnvDir overrides the cache directory.
+	EnvDir = "AI_MAP_CACHE_DIR"
+)
+
+// layout names the on-disk format; Clean only removes directories it owns.
+const layout = "v1"
+
+// EnabledByEnv reports whether EnvEnable asks for caching.
+func EnabledByEnv() bool {
+	switch strings.ToLower(strings.TrimSpace(os.Getenv(EnvEnable))) {
+	case "1", "true", "yes", "on":
+		return true
+	default:
+		return false
+	}
+}
+
+// DefaultDir is $AI_MAP_CACHE_DIR, or ai-map under the user cache directory.
+func DefaultDir() (string, error) {
+	if d := strings.TrimSpace(os.Getenv(EnvDir)); d != "" {
+		return d, nil
+	}
+	base, err := os.UserCacheDir()
+	if err != nil {
+		return "", fmt.Errorf("cannot locate user cache dir (set %s): %w", EnvDir, err)
+	}
+	return filepath.Join(base, "ai-map"), nil
+}
+
+// Key hashes its parts into a cache key. Parts are length-prefixed, so
+// ("ab", "c") and ("a", "bc") differ.
+func Key(parts ...[]byte) string {
+	h := sha256.New()
+	for _, p := range parts {
+		fmt.Fprintf(h, "%d:", len(p))
+		h.Write(p)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// Cache is a directory of JSON entries grouped by namespace (e.g. "validate").
+type Cache struct {
+	dir string
+}
+
+// Open returns a cache rooted at dir; the directory is created on first write.
+func Open(dir string) *Cache {
+	return &Cache{dir: dir}
+}
+
+func (c *Cache) path(ns, key string) string {
+	return filepath.Join(c.dir, layout, ns, key[:2], key+".json")
+}
+
+// Get decodes the entry for key into v and reports whether it was found.
+func (c *Cache) Get(ns, key string, v any) bool {
+	b, err := os.ReadFile(c.path(ns, key))
+	if err != nil {
+		return false
+	}
+	return json.Unmarshal(b, v) == nil
+}
+
+// Put stores v under key, atomically replacing any previous entry.
+func (c *Cache) Put(ns, key string, v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	p := c.path(ns, key)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(p), "."+key+".tmp-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), p)
+}
+
+// Clean removes every entry under dir and reports how many were removed.
+func Clean(dir string) (int, error) {
+	root := filepath.Join(dir, layout)
+	n := 0
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && strings.HasSuffix(d.Name(), ".json") {
+			n++
+		}
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	return n, os.RemoveAll(root)
+}
@@ -0,0 +1,72 @@
+package cache
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+type entry struct {
+	OK     bool
+	Errors []string
+}
+
+func TestPutGetClean(t *testing.T) {
+	dir := t.TempDir()
+	c := Open(dir)
+	key := Key([]byte("content"), []byte("schema-hash"))
+	if key == Key([]byte("contents"), []byte("chema-hash")) {
+		t.Fatal("keys must be length-prefixed")
+	}
+
+	var got entry
+	if c.Get("validate", key, &got) {
+		t.Fatal("unexpected hit on empty cache")
+	}
+	want := entry{Errors: []string{"/system: missing"}}
+	if err := c.Put("validate", key, want); err != nil {
+		t.Fatal(err)
+	}
+	if !c.Get("validate", key, &got) || got.OK || len(got.Errors) != 1 || got.Errors[0] != want.Errors[0] {
+		t.Fatalf("Get = %+v, want %+v", got, want)
+	}
+	if c.Get("lint", key, &got) {
+		t.Fatal("namespaces must not share entries")
+	}
+
+	n, err := Clean(dir)
+	if err != nil || n != 1 {
+		t.Fatalf("Clean = %d, %v; want 1, nil", n, err)
+	}
+	if c.Get("validate", key, &got) {
+		t.Fatal("entry survived Clean")
+	}
+	if n, err := Clean(dir); err != nil || n != 0 {
+		t.Fatalf("Clean on empty cache = %d, %v", n, err)
+	}
+}
+
+func TestConcurrentWriters(t *testing.T) {
+	dir := t.TempDir()
+	key := Key([]byte("same"))
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := Open(dir) // as if from separate processes
+			for j := 0; j < 20; j++ {
+				if err := c.Put("lint", key, entry{Errors: []string{fmt.Sprint(i)}}); err != nil {
+					t.Error(err)
+					return
+				}
+				var got entry
+				if !c.Get("lint", key, &got) || len(got.Errors) != 1 {
+					t.Errorf("torn or missing entry: %+v", got)
+					return
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,161 @@
+// Package catalog indexes the maps of a repository for lookup by system name
+// and by path, and serves the index as a read-only JSON API.
+package catalog
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/discover"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/resolve"
+)
+
+// System is one map in the index.
+type System struct {
+	// Name is system.name; it may be empty for maps that omit it.
+	Name string
+	// Path is the map file and Dir the directory it governs, both relative to the root.
+	Path string
+	Dir  string
+	// Effective is the map after inheritance from ancestor maps (see package resolve).
+	Effective resolve.Effective
+}
+
+// Index is an immutable snapshot of every map under a root.
+type Index struct {
+	Root    string
+	Systems []*System
+	// Errors lists maps that could not be read or parsed, as "path: message".
+	Errors []string
+}
+
+// Load discovers and indexes the maps under root. Unreadable or invalid maps
+// are recorded in Errors rather than failing the whole index.
+func Load(root string, opt discover.Options) (*Index, error) {
+	abs, err := filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+	paths, err := discover.Find(abs, opt)
+	if err != nil {
+		return nil, err
+	}
+
+	idx := &Index{Root: abs}
+	var layers []resolve.Layer
+	for _, p := range paths {
+		rel, _ := aimap.RelTo(abs, p)
+		b, err := input.ReadFileWithLimit(p, input.MaxYAMLBytes)
+		if err != nil {
+			idx.Errors = append(idx.Errors, fmt.Sprintf("%s: %s", rel, err))
+			continue
+		}
+		doc, err := aimap.Decode(b)
+		if err != nil {
+			idx.Errors = append(idx.Errors, fmt.Sprintf("%s: %s", rel, err))
+			continue
+		}
+		dir, _ := aimap.RelTo(abs, filepath.Dir(p))
+		layers = append(layers, resolve.Layer{Path: rel, Dir: dir, Doc: doc})
+	}
+
+	for _, l := range layers {
+		var chain []resolve.Layer
+		for _, a := range layers {
+			if within(l.Dir, a.Dir) {
+				chain = append(chain, a)
+			}
+		}
+		sort.SliceStable(chain, func(i, j int) bool { return depth(chain[i].Dir) < depth(chain[j].Dir) })
+		s := &System{Path: l.Path, Dir: l.Dir, Effective: resolve.Resolve(chain)}
+		if sys, ok := l.Doc["system"].(map[string]any); ok {
+			s.Name, _ = sys["name"].(string)
+		}
+		idx.Systems = append(idx.Systems, s)
+	}
+	sort.SliceStable(idx.Systems, func(i, j int) bool {
+		if idx.Systems[i].Name != idx.Systems[j].Name {
+			return idx.Systems[i].Name < idx.Systems[j].Name
+		}
+		return idx.Systems[i].Path < idx.Systems[j].Path
+	})
+	return idx, nil
+}
+
+// Lookup returns every system named name; more than one means the name is ambiguous.
+func (idx *Index) Lookup(name string) []*System {
+	var out []*System
+	for _, s := range idx.Systems {
+		if s.Name == name {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// Owner returns the system governing rel (a slash-separated path relative to
+// the root): the map in the deepest directory containing it.
+func (idx *Index) Owner(rel string) (*System, bool) {
+	rel = aimap.CleanPath(rel)
+	var best *System
+	for _, s := range idx.Systems {
+		if within(rel, s.Dir) && (best == nil || depth(s.Dir) > depth(best.Dir)) {
+			best = s
+		}
+	}
+	return best, best != nil
+}
+
+// Dependents returns the systems listing name in dependencies.internal.
+func (idx *Index) Dependents(name string) []*System {
+	var out []*System
+	for _, s := range idx.Systems {
+		for _, d := range s.Internal() {
+			if d == name {
+				out = append(out, s)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// Internal returns the effective dependencies.internal list.
+func (s *System) Internal() []string {
+	deps, _ := s.Effective.Doc["dependencies"].(map[string]any)
+	list, _ := deps["internal"].([]any)
+	var out []string
+	for _, v := range list {
+		if str, ok := v.(string); ok {
+			out = append(out, str)
+		}
+	}
+	return out
+}
+
+// field returns a string at section.key of the effective map ("" if absent).
+func (s *System) field(section, key string) string {
+	m, _ := s.Effective.Doc[section].(map[string]any)
+	v, _ := m[key].(string)
+	return v
+}
+
+// within reports whether rel is dir or below it; "." contains everything.
+func within(rel, dir string) bool {
+	if dir == "." || dir == "" {
+		return true
+	}
+	return rel == dir || strings.HasPrefix(rel, dir+"/")
+}
+
+func depth(dir string) int {
+	if dir == "." || dir == "" {
+		return 0
+	}
+	return strings.Count(path.Clean(dir), "/") + 1
+}
@@ -0,0 +1,110 @@
+package catalog
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/discover"
+)
+
+func writeMap(t *testing.T, root, rel, body string) {
+	t.Helper()
+	p := filepath.Join(root, rel)
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func get(t *testing.T, srv *httptest.Server, path, etag string) (int, http.Header, map[string]any) {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodGet, srv.URL+path, nil)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	b, _ := io.ReadAll(resp.Body)
+	var v map[string]any
+	if len(b) > 0 {
+		if err := json.Unmarshal(b, &v); err != nil {
+			t.Fatalf("GET %s: invalid JSON %q", path, b)
+		}
+	}
+	return resp.StatusCode, resp.Header, v
+}
+
+func TestServer(t *testing.T) {
+	root := t.TempDir()
+	writeMap(t, root, ".ai-map.yaml", "version: 1\nsystem:\n  name: platform\n  type: monorepo\nownership:\n  team: core\n  slack: \"#core\"\n")
+	writeMap(t, root, "services/api/.ai-map.yaml", "version: 1\nsystem:\n  name: api\n  type: service\ndependencies:\n  internal: [billing]\nownership:\n  team: api-team\n")
+	writeMap(t, root, "services/billing/.ai-map.yaml", "version: 1\nsystem:\n  name: billing\n  type: service\n")
+	writeMap(t, root, "broken/.ai-map.yaml", "version: [\n")
+
+	idx, err := Load(root, discover.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := NewServer(idx)
+	srv := httptest.NewServer(s)
+	defer srv.Close()
+
+	code, h, body := get(t, srv, "/v1/systems", "")
+	if code != http.StatusOK || h.Get("ETag") == "" {
+		t.Fatalf("list: %d etag=%q", code, h.Get("ETag"))
+	}
+	if n := len(body["systems"].([]any)); n != 3 {
+		t.Fatalf("list: %d systems, want 3", n)
+	}
+	if n := len(body["errors"].([]any)); n != 1 {
+		t.Fatalf("list: %d errors, want 1", n)
+	}
+	if code, _, _ := get(t, srv, "/v1/systems", h.Get("ETag")); code != http.StatusNotModified {
+		t.Fatalf("conditional list: %d, want 304", code)
+	}
+
+	_, _, body = get(t, srv, "/v1/systems/billing", "")
+	if body["team"] != "core" || body["map"] != "services/billing/.ai-map.yaml" {
+		t.Fatalf("billing should inherit the root team: %v", body)
+	}
+	if code, _, _ := get(t, srv, "/v1/systems/nope", ""); code != http.StatusNotFound {
+		t.Fatalf("unknown system: %d, want 404", code)
+	}
+
+	_, _, body = get(t, srv, "/v1/systems/billing/dependents", "")
+	deps := body["dependents"].([]any)
+	if len(deps) != 1 || deps[0].(map[string]any)["name"] != "api" {
+		t.Fatalf("dependents: %v", body)
+	}
+
+	_, _, body = get(t, srv, "/v1/owner?path=services/api/src/main.go", "")
+	own := body["ownership"].(map[string]any)
+	if body["system"] != "api" || own["team"] != "api-team" || own["slack"] != "#core" {
+		t.Fatalf("owner: %v", body)
+	}
+	if code, _, _ := get(t, srv, "/v1/owner?path=../etc", ""); code != http.StatusBadRequest {
+		t.Fatalf("owner outside root: %d, want 400", code)
+	}
+
+	// Reloading changes the content and therefore the ETag.
+	writeMap(t, root, "services/billing/.ai-map.yaml", "version: 1\nsystem:\n  name: billing\n  type: service\nownership:\n  team: money\n")
+	next, err := Load(root, discover.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Set(next)
+	code, h2, body := get(t, srv, "/v1/systems/billing", h.Get("ETag"))
+	if code != http.StatusOK || body["team"] != "money" || h2.Get("ETag") == h.Get("ETag") {
+		t.Fatalf("after reload: %d %v", code, body)
+	}
+}
@@ -0,0 +1,192 @@
+package catalog
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cjson"
+)
+
+// Server serves an Index over HTTP. The index can be swapped at any time with
+// Set; requests in flight keep the snapshot they started with.
+//
+// Endpoints (all GET, all canonical JSON):
+//
+//	/v1/systems                    every system, summarised
+//	/v1/systems/{name}             one system with its effective map
+//	/v1/systems/{name}/dependents  systems listing {name} in dependencies.internal
+//	/v1/owner?path=P               the system and ownership governing path P
+//
+// Every response carries an ETag derived from its body; a matching
+// If-None-Match yields 304 Not Modified.
+type Server struct {
+	idx atomic.Pointer[Index]
+	mux *http.ServeMux
+}
+
+// NewServer returns a server for idx.
+func NewServer(idx *Index) *Server {
+	s := &Server{mux: http.NewServeMux()}
+	s.idx.Store(idx)
+	s.mux.HandleFunc("GET /v1/systems", s.systems)
+	s.mux.HandleFunc("GET /v1/systems/{name}", s.system)
+	s.mux.HandleFunc("GET /v1/systems/{name}/dependents", s.dependents)
+	s.mux.HandleFunc("GET /v1/owner", s.owner)
+	s.mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		writeError(w, r, http.StatusNotFound, "no such endpoint")
+	})
+	return s
+}
+
+// Set replaces the served index.
+func (s *Server) Set(idx *Index) { s.idx.Store(idx) }
+
+// Index returns the served index.
+func (s *Server) Index() *Index { return s.idx.Load() }
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) { s.mux.ServeHTTP(w, r) }
+
+func (s *Server) systems(w http.ResponseWriter, r *http.Request) {
+	idx := s.Index()
+	list := make([]any, 0, len(idx.Systems))
+	for _, sys := range idx.Systems {
+		list = append(list, summary(sys))
+	}
+	errs := make([]any, 0, len(idx.Errors))
+	for _, e := range idx.Errors {
+		errs = append(errs, e)
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{"systems": list, "errors": errs})
+}
+
+func (s *Server) system(w http.ResponseWriter, r *http.Request) {
+	sys, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	out := summary(sys)
+	out["effective"] = sys.Effective.Doc
+	chain := make([]any, 0, len(sys.Effective.Chain))
+	for _, c := range sys.Effective.Chain {
+		chain = append(chain, c)
+	}
+	out["chain"] = chain
+	writeJSON(w, r, http.StatusOK, out)
+}
+
+func (s *Server) dependents(w http.ResponseWriter, r *http.Request) {
+	sys, ok := s.lookup(w, r)
+	if !ok {
+		return
+	}
+	list := []any{}
+	for _, d := range s.Index().Dependents(sys.Name) {
+		list = append(list, summary(d))
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{"system": sys.Name, "dependents": list})
+}
+
+func (s *Server) owner(w http.ResponseWriter, r *http.Request) {
+	p := r.URL.Query().Get("path")
+	if p == "" {
+		writeError(w, r, http.StatusBadRequest, "missing path parameter")
+		return
+	}
+	rel := aimap.CleanPath(p)
+	if rel == ".." || strings.HasPrefix(rel, "../") {
+		writeError(w, r, http.StatusBadRequest, "path must be inside the repository")
+		return
+	}
+	sys, ok := s.Index().Owner(rel)
+	if !ok {
+		writeError(w, r, http.StatusNotFound, "no map governs "+rel)
+		return
+	}
+	ownership, _ := sys.Effective.Doc["ownership"].(map[string]any)
+	if ownership == nil {
+		ownership = map[string]any{}
+	}
+	sources := map[string]any{}
+	for k, v := range sys.Effective.Sources {
+		if strings.HasPrefix(k, "ownership.") {
+			sources[k] = v
+		}
+	}
+	writeJSON(w, r, http.StatusOK, map[string]any{
+		"path":      rel,
+		"system":    sys.Name,
+		"map":       sys.Path,
+		"ownership": ownership,
+		"sources":   sources,
+	})
+}
+
+// lookup resolves {name}, writing 404 or 409 (ambiguous name) on failure.
+func (s *Server) lookup(w http.ResponseWriter, r *http.Request) (*System, bool) {
+	name := r.PathValue("name")
+	found := s.Index().Lookup(name)
+	switch len(found) {
+	case 0:
+		writeError(w, r, http.StatusNotFound, "unknown system "+name)
+		return nil, false
+	case 1:
+		return found[0], true
+	}
+	maps := make([]string, 0, len(found))
+	for _, f := range found {
+		maps = append(maps, f.Path)
+	}
+	writeError(w, r, http.StatusConflict, "system "+name+" is defined by several maps: "+strings.Join(maps, ", "))
+	return nil, false
+}
+
+func summary(s *System) map[string]any {
+	return map[string]any{
+		"name":   s.Name,
+		"map":    s.Path,
+		"dir":    s.Dir,
+		"type":   s.field("system", "type"),
+		"domain": s.field("system", "domain"),
+		"team":   s.field("ownership", "team"),
+	}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, msg string) {
+	writeJSON(w, r, status, map[string]any{"error": msg})
+}
+
+// writeJSON writes v as canonical JSON with a content-hash ETag, answering
+// conditional requests with 304.
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	b, err := cjson.MarshalIndent(v, "", "  ")
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	sum := sha256.Sum256(b)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+	h := w.Header()
+	h.Set("Content-Type", "application/json")
+	h.Set("ETag", etag)
+	h.Set("Cache-Control", "no-cache")
+	if status == http.StatusOK && etagMatch(r.Header.Get("If-None-Match"), etag) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.WriteHeader(status)
+	w.Write(b)
+}
+
+func etagMatch(header, etag string) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimPrefix(strings.TrimSpace(t), "W/")
+		if t == etag || t == "*" {
+			return true
+		}
+	}
+	return false
+}
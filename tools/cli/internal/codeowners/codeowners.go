@@ -0,0 +1,250 @@
+// Package codeowners generates CODEOWNERS entries from map ownership data and
+// checks an existing CODEOWNERS file for contradictions.
+package codeowners
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/ignore"
+	"gopkg.in/yaml.v3"
+)
+
+// Teams maps ownership.team values to CODEOWNERS handles (e.g. "@org/assets").
+type Teams map[string][]string
+
+// teamsFile is the on-disk format:
+//
+//	teams:
+//	  assets-platform: "@org/assets"
+//	  payments: ["@org/payments", "@alice"]
+type teamsFile struct {
+	Teams map[string]any `yaml:"teams"`
+}
+
+// LoadTeams reads the team-to-handle mapping from a YAML file.
+func LoadTeams(path string) (Teams, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var f teamsFile
+	if err := yaml.Unmarshal(b, &f); err != nil {
+		return nil, fmt.Errorf("YAML parse error: %w", err)
+	}
+	out := Teams{}
+	for team, raw := range f.Teams {
+		var handles []string
+		switch v := raw.(type) {
+		case string:
+			handles = []string{v}
+		case []any:
+			for _, it := range v {
+				s, ok := it.(string)
+				if !ok {
+					return nil, fmt.Errorf("teams.%s: handles must be strings", team)
+				}
+				handles = append(handles, s)
+			}
+		default:
+			return nil, fmt.Errorf("teams.%s: expected a handle or a list of handles", team)
+		}
+		for _, h := range handles {
+			if !strings.Contains(h, "@") {
+				return nil, fmt.Errorf("teams.%s: %q is not a @user, @org/team or email handle", team, h)
+			}
+		}
+		out[team] = handles
+	}
+	return out, nil
+}
+
+// Entry is one generated CODEOWNERS line.
+type Entry struct {
+	Pattern string
+	Owners  []string
+	// Source explains where the entry came from (map path and field).
+	Source string
+}
+
+// Generate builds entries for every map: one for the map's directory, then one per
+// critical path. Directory entries are ordered by depth so nested maps override
+// their ancestors, and critical entries come last so they win over both
+// (CODEOWNERS applies the last matching line). Maps whose team has no handle are
+// skipped with a warning.
+func Generate(maps []aimap.Located, teams Teams) (entries []Entry, warnings []string) {
+	sorted := append([]aimap.Located(nil), maps...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		di, dj := depth(sorted[i].Dir), depth(sorted[j].Dir)
+		if di != dj {
+			return di < dj
+		}
+		return sorted[i].Dir < sorted[j].Dir
+	})
+
+	var critical []Entry
+	for _, m := range sorted {
+		team := strings.TrimSpace(m.Map.Ownership.Team)
+		if team == "" {
+			warnings = append(warnings, fmt.Sprintf("%s: no ownership.team; skipped", m.Path))
+			continue
+		}
+		handles, ok := teams[team]
+		if !ok || len(handles) == 0 {
+			warnings = append(warnings, fmt.Sprintf("%s: no handle configured for team %q; skipped", m.Path, team))
+			continue
+		}
+		dirPattern := "*"
+		if m.Dir != "." {
+			dirPattern = "/" + m.Dir + "/"
+		}
+		entries = append(entries, Entry{Pattern: dirPattern, Owners: handles, Source: m.Path + " ownership.team"})
+		for _, c := range m.Map.Boundaries.Critical {
+			critical = append(critical, Entry{Pattern: rebase(m.Dir, c), Owners: handles, Source: m.Path + " boundaries.critical"})
+		}
+	}
+	return append(entries, critical...), warnings
+}
+
+// Format renders entries as a CODEOWNERS file.
+func Format(entries []Entry) []byte {
+	var b bytes.Buffer
+	b.WriteString("# Generated by `ai-map codeowners generate` from .ai-map.yaml ownership data.\n")
+	b.WriteString("# Edit the maps or the team handle config instead of this file.\n")
+	width := 0
+	for _, e := range entries {
+		if len(e.Pattern) > width {
+			width = len(e.Pattern)
+		}
+	}
+	for _, e := range entries {
+		fmt.Fprintf(&b, "%-*s %s\n", width, e.Pattern, strings.Join(e.Owners, " "))
+	}
+	return b.Bytes()
+}
+
+// Line is one parsed CODEOWNERS rule.
+type Line struct {
+	Number  int
+	Pattern string
+	Owners  []string
+	rule    ignore.Rule
+}
+
+// Parse reads CODEOWNERS rules, skipping comments and blank lines.
+func Parse(b []byte) []Line {
+	var out []Line
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	n := 0
+	for sc.Scan() {
+		n++
+		text := sc.Text()
+		if i := strings.Index(text, " #"); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(text)
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		r, ok := ignore.ParseRule(fields[0])
+		if !ok {
+			continue
+		}
+		out = append(out, Line{Number: n, Pattern: fields[0], Owners: fields[1:], rule: r})
+	}
+	return out
+}
+
+// Problem is a place where CODEOWNERS disagrees with the maps.
+type Problem struct {
+	Pattern string
+	Want    []string
+	// Line is the governing CODEOWNERS line (nil when nothing covers the pattern).
+	Line   *Line
+	Source string
+}
+
+func (p Problem) String() string {
+	if p.Line == nil {
+		return fmt.Sprintf("%s: no CODEOWNERS rule; maps say %s (%s)", p.Pattern, strings.Join(p.Want, " "), p.Source)
+	}
+	have := strings.Join(p.Line.Owners, " ")
+	if have == "" {
+		have = "(no owners)"
+	}
+	return fmt.Sprintf("%s: line %d (%s) assigns %s; maps say %s (%s)",
+		p.Pattern, p.Line.Number, p.Line.Pattern, have, strings.Join(p.Want, " "), p.Source)
+}
+
+// Check reports each generated entry whose effective owners in lines differ.
+// Literal patterns are resolved the way GitHub does (last matching line wins);
+// glob patterns must appear verbatim.
+func Check(want []Entry, lines []Line) []Problem {
+	var out []Problem
+	for _, e := range want {
+		gov := governing(e.Pattern, lines)
+		if gov == nil {
+			out = append(out, Problem{Pattern: e.Pattern, Want: e.Owners, Source: e.Source})
+			continue
+		}
+		if !sameOwners(gov.Owners, e.Owners) {
+			out = append(out, Problem{Pattern: e.Pattern, Want: e.Owners, Line: gov, Source: e.Source})
+		}
+	}
+	return out
+}
+
+func governing(pattern string, lines []Line) *Line {
+	probe := strings.Trim(pattern, "/")
+	literal := pattern != "*" && !strings.ContainsAny(probe, "*?[")
+	for i := len(lines) - 1; i >= 0; i-- {
+		l := &lines[i]
+		if l.Pattern == pattern {
+			return l
+		}
+		if literal && l.rule.MatchTree(probe, true) {
+			return l
+		}
+	}
+	return nil
+}
+
+func sameOwners(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	as := append([]string(nil), a...)
+	bs := append([]string(nil), b...)
+	for i := range as {
+		as[i] = strings.ToLower(as[i])
+		bs[i] = strings.ToLower(bs[i])
+	}
+	sort.Strings(as)
+	sort.Strings(bs)
+	for i := range as {
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func rebase(dir, p string) string {
+	p = aimap.CleanPath(p)
+	if dir == "." {
+		return "/" + p
+	}
+	return "/" + dir + "/" + p
+}
+
+func depth(dir string) int {
+	if dir == "." {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
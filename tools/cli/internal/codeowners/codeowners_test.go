@@ -0,0 +1,46 @@
+package codeowners
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+func TestGenerateAndCheck(t *testing.T) {
+	parse := func(s string) *aimap.Map {
+		m, err := aimap.Parse([]byte(s))
+		if err != nil {
+			t.Fatalf("Parse: %v", err)
+		}
+		return m
+	}
+	maps := []aimap.Located{
+		{Path: "svc/b/.ai-map.yaml", Dir: "svc/b", Map: parse("ownership: {team: bteam}\nboundaries: {critical: [core]}\n")},
+		{Path: ".ai-map.yaml", Dir: ".", Map: parse("ownership: {team: platform}\n")},
+		{Path: "svc/c/.ai-map.yaml", Dir: "svc/c", Map: parse("ownership: {team: nobody}\n")},
+	}
+	teams := Teams{"platform": {"@org/platform"}, "bteam": {"@org/b"}}
+
+	entries, warnings := Generate(maps, teams)
+	got := string(Format(entries))
+	for _, want := range []string{"*           @org/platform\n", "/svc/b/     @org/b\n", "/svc/b/core @org/b\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("generated CODEOWNERS missing %q:\n%s", want, got)
+		}
+	}
+	if strings.Index(got, "/svc/b/ ") > strings.Index(got, "/svc/b/core") {
+		t.Errorf("critical entries must follow directory entries:\n%s", got)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "nobody") {
+		t.Errorf("expected a warning for the unmapped team, got %v", warnings)
+	}
+
+	if p := Check(entries, Parse([]byte(got))); len(p) != 0 {
+		t.Fatalf("generated file should check clean, got %v", p)
+	}
+	p := Check(entries, Parse([]byte("* @org/platform\n/svc/ @someone # stale\n")))
+	if len(p) != 2 || p[0].Line == nil || p[0].Line.Number != 2 {
+		t.Fatalf("expected two contradictions governed by line 2, got %v", p)
+	}
+}
@@ -0,0 +1,251 @@
+// Package config loads the project-level tool configuration (.ai-map-tool.yaml).
+//
+// The file is found by walking up from the working directory. Each setting is a
+// default for a command-line flag of the same meaning; flags given explicitly
+// always win. Relative paths in the file are resolved against its directory.
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/lint"
+	"gopkg.in/yaml.v3"
+)
+
+// FileName is the tool configuration file looked up from the working directory.
+const FileName = ".ai-map-tool.yaml"
+
+// maxConfigBytes caps the config file read; it is independent of max_bytes.
+const maxConfigBytes = 1 << 20
+
+// SourceDefault marks a setting that no config file sets.
+const SourceDefault = "default"
+
+// File mirrors the YAML document. Pointers distinguish "unset" from zero values.
+type File struct {
+	Schema            *string `yaml:"schema"`
+	ExtensionsDir     *string `yaml:"extensions_dir"`
+	UnknownExtensions *string `yaml:"unknown_extensions"`
+	Input             struct {
+		Dir       *string  `yaml:"dir"`
+		Recursive *bool    `yaml:"recursive"`
+		Discover  *bool    `yaml:"discover"`
+		MapName   *string  `yaml:"map_name"`
+		Include   []string `yaml:"include"`
+		Exclude   []string `yaml:"exclude"`
+	} `yaml:"input"`
+	Lint struct {
+		Rules map[string]string `yaml:"rules"`
+	} `yaml:"lint"`
+	Detect struct {
+		InternalPrefixes []string `yaml:"internal_prefixes"`
+	} `yaml:"detect"`
+	Format   *string `yaml:"format"`
+	Jobs     *int    `yaml:"jobs"`
+	Cache    *bool   `yaml:"cache"`
+	CacheDir *string `yaml:"cache_dir"`
+	MaxBytes *int64  `yaml:"max_bytes"`
+}
+
+// Setting is one effective configuration value.
+type Setting struct {
+	// Key is the dotted config key, e.g. "input.discover".
+	Key string
+	// Flag is the command-line flag the setting defaults.
+	Flag string
+	// Values are the flag values; repeatable flags may have several, unset settings none.
+	Values []string
+	// Source is the config file path, or SourceDefault.
+	Source string
+	// Commands are the commands whose flag the setting defaults, by path below
+	// the root command (e.g. "cache clean"). Other commands ignore it.
+	Commands []string
+}
+
+// AppliesTo reports whether the setting defaults a flag of the named command.
+func (s Setting) AppliesTo(command string) bool {
+	for _, c := range s.Commands {
+		if c == command {
+			return true
+		}
+	}
+	return false
+}
+
+// Config is a loaded (possibly empty) tool configuration.
+type Config struct {
+	// Path is the file the settings came from; empty when none was found.
+	Path string
+	file File
+}
+
+// Find walks up from dir and returns the first FileName found.
+func Find(dir string) (string, bool) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", false
+	}
+	for {
+		p := filepath.Join(abs, FileName)
+		if st, err := os.Stat(p); err == nil && !st.IsDir() {
+			return p, true
+		}
+		parent := filepath.Dir(abs)
+		if parent == abs {
+			return "", false
+		}
+		abs = parent
+	}
+}
+
+// Load reads and checks a config file. Unknown keys are errors, so typos surface.
+func Load(path string) (*Config, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	st, err := os.Stat(abs)
+	if err != nil {
+		return nil, err
+	}
+	if st.Size() > maxConfigBytes {
+		return nil, fmt.Errorf("file too large (%d bytes > %d)", st.Size(), maxConfigBytes)
+	}
+	b, err := os.ReadFile(abs)
+	if err != nil {
+		return nil, err
+	}
+
+	var f File
+	dec := yaml.NewDecoder(bytes.NewReader(b))
+	dec.KnownFields(true)
+	if err := dec.Decode(&f); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if f.Jobs != nil && *f.Jobs < 1 {
+		return nil, errors.New("jobs must be at least 1")
+	}
+	if f.MaxBytes != nil && *f.MaxBytes <= 0 {
+		return nil, errors.New("max_bytes must be > 0")
+	}
+	for name, sev := range f.Lint.Rules {
+		if _, _, err := lint.ParseRuleSetting(name + "=" + sev); err != nil {
+			return nil, fmt.Errorf("lint.rules: %w", err)
+		}
+	}
+
+	dir := filepath.Dir(abs)
+	for _, p := range []*string{f.Schema, f.ExtensionsDir, f.Input.Dir, f.CacheDir} {
+		if p != nil && *p != "" && !filepath.IsAbs(*p) {
+			*p = filepath.Join(dir, filepath.FromSlash(*p))
+		}
+	}
+	return &Config{Path: abs, file: f}, nil
+}
+
+// MaxBytes returns max_bytes and whether the file sets it.
+func (c *Config) MaxBytes() (int64, bool) {
+	if c.file.MaxBytes == nil {
+		return 0, false
+	}
+	return *c.file.MaxBytes, true
+}
+
+// Settings lists every supported key in a fixed order, with its value and source.
+func (c *Config) Settings() []Setting {
+	f := c.file
+	var out []Setting
+	add := func(key, flag string, values []string, set bool) {
+		s := Setting{Key: key, Flag: flag, Source: SourceDefault, Commands: commands[key]}
+		if set {
+			s.Values = values
+			s.Source = c.Path
+		}
+		out = append(out, s)
+	}
+	str := func(key, flag string, v *string) {
+		if v == nil {
+			add(key, flag, nil, false)
+			return
+		}
+		add(key, flag, []string{*v}, true)
+	}
+	boolean := func(key, flag string, v *bool) {
+		if v == nil {
+			add(key, flag, nil, false)
+			return
+		}
+		add(key, flag, []string{strconv.FormatBool(*v)}, true)
+	}
+
+	str("schema", "schema", f.Schema)
+	str("extensions_dir", "extensions-dir", f.ExtensionsDir)
+	str("unknown_extensions", "unknown-extensions", f.UnknownExtensions)
+	str("input.dir", "dir", f.Input.Dir)
+	boolean("input.recursive", "recursive", f.Input.Recursive)
+	boolean("input.discover", "discover", f.Input.Discover)
+	str("input.map_name", "map-name", f.Input.MapName)
+	add("input.include", "include", f.Input.Include, f.Input.Include != nil)
+	add("input.exclude", "exclude", f.Input.Exclude, f.Input.Exclude != nil)
+
+	var rules []string
+	for name, sev := range f.Lint.Rules {
+		rules = append(rules, name+"="+sev)
+	}
+	sort.Strings(rules)
+	add("lint.rules", "rule", rules, f.Lint.Rules != nil)
+
+	add("detect.internal_prefixes", "internal-prefix", f.Detect.InternalPrefixes, f.Detect.InternalPrefixes != nil)
+	str("format", "format", f.Format)
+	if f.Jobs != nil {
+		add("jobs", "jobs", []string{strconv.Itoa(*f.Jobs)}, true)
+	} else {
+		add("jobs", "jobs", nil, false)
+	}
+	boolean("cache", "cache", f.Cache)
+	str("cache_dir", "cache-dir", f.CacheDir)
+	if f.MaxBytes != nil {
+		add("max_bytes", "max-bytes", []string{strconv.FormatInt(*f.MaxBytes, 10)}, true)
+	} else {
+		add("max_bytes", "max-bytes", nil, false)
+	}
+	return out
+}
+
+// commands scopes each key to the commands where its flag has the meaning the
+// key documents. A flag of the same name elsewhere (e.g. context's --format,
+// serve's --dir) is not touched. max_bytes sets a root flag and applies to all.
+var commands = map[string][]string{
+	"schema":                   {"validate", "watch", "lsp"},
+	"extensions_dir":           {"validate", "watch", "lsp"},
+	"unknown_extensions":       {"validate", "watch", "lsp"},
+	"input.dir":                inputCommands,
+	"input.recursive":          inputCommands,
+	"input.discover":           inputCommands,
+	"input.map_name":           append(inputCommands[:len(inputCommands):len(inputCommands)], "watch", "serve"),
+	"input.include":            append(inputCommands[:len(inputCommands):len(inputCommands)], "watch", "serve"),
+	"input.exclude":            append(inputCommands[:len(inputCommands):len(inputCommands)], "watch", "serve"),
+	"lint.rules":               {"lint", "watch", "lsp"},
+	"detect.internal_prefixes": {"detect"},
+	"format":                   {"diff", "guard", "permissions", "drift", "coverage"},
+	"jobs":                     {"validate", "lint", "render"},
+	"cache":                    {"validate", "lint"},
+	"cache_dir":                {"validate", "lint", "cache clean"},
+}
+
+// inputCommands are the commands that select files with the shared input flags.
+var inputCommands = []string{"validate", "lint", "render", "migrate", "export"}
+
+// InputKeys are the settings that pick input files. They are skipped when a
+// command is given explicit file arguments, which would otherwise conflict.
+var InputKeys = map[string]bool{
+	"input.dir": true, "input.recursive": true, "input.discover": true,
+	"input.map_name": true, "input.include": true, "input.exclude": true,
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindAndLoad(t *testing.T) {
+	root := t.TempDir()
+	sub := filepath.Join(root, "a", "b")
+	if err := os.MkdirAll(sub, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cfgPath := filepath.Join(root, FileName)
+	doc := "schema: schemas/v1.json\ninput:\n  discover: true\n  include: [\"svc/**\"]\nlint:\n  rules:\n    system-type: error\nmax_bytes: 1024\n"
+	if err := os.WriteFile(cfgPath, []byte(doc), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, ok := Find(sub)
+	if !ok || p != cfgPath {
+		t.Fatalf("Find = %q, %v; want %q", p, ok, cfgPath)
+	}
+	cfg, err := Load(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n, ok := cfg.MaxBytes(); !ok || n != 1024 {
+		t.Fatalf("MaxBytes = %d, %v", n, ok)
+	}
+
+	got := map[string]Setting{}
+	for _, s := range cfg.Settings() {
+		got[s.Key] = s
+	}
+	if s := got["schema"]; s.Source != cfgPath || s.Values[0] != filepath.Join(root, "schemas", "v1.json") {
+		t.Fatalf("schema = %+v", s)
+	}
+	if s := got["input.include"]; s.Flag != "include" || strings.Join(s.Values, ",") != "svc/**" {
+		t.Fatalf("input.include = %+v", s)
+	}
+	if s := got["lint.rules"]; s.Flag != "rule" || s.Values[0] != "system-type=error" {
+		t.Fatalf("lint.rules = %+v", s)
+	}
+	if s := got["jobs"]; s.Source != SourceDefault || s.Values != nil {
+		t.Fatalf("jobs = %+v", s)
+	}
+}
+
+func TestLoadRejects(t *testing.T) {
+	for _, doc := range []string{
+		"shcema: x.json\n",
+		"jobs: 0\n",
+		"lint:\n  rules:\n    no-such-rule: warn\n",
+		"lint:\n  rules:\n    system-type: loud\n",
+	} {
+		p := filepath.Join(t.TempDir(), FileName)
+		if err := os.WriteFile(p, []byte(doc), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Load(p); err == nil {
+			t.Errorf("Load(%q): expected error", doc)
+		}
+	}
+}
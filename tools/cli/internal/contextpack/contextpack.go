@@ -0,0 +1,468 @@
+// Package contextpack builds a prompt-ready bundle for an agent starting a task:
+// a map's essentials and the files most relevant to an optional focus, within
+// an approximate token budget. The same inputs always give the same pack.
+package contextpack
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/sources"
+)
+
+// DefaultBudget is the token budget when none is given.
+const DefaultBudget = 8000
+
+// maxScanBytes skips large files when searching for a keyword.
+const maxScanBytes = 1 << 20
+
+// Focus kinds.
+const (
+	FocusNone     = ""
+	FocusProtocol = "protocol"
+	FocusPath     = "path"
+	FocusKeyword  = "keyword"
+)
+
+// Options tunes Build.
+type Options struct {
+	// Focus is an entrypoint protocol, a path relative to the map directory, or
+	// a keyword, tried in that order.
+	Focus string
+	// Budget is the approximate token budget (DefaultBudget when zero).
+	Budget int
+}
+
+// File is one ranked file.
+type File struct {
+	// Path is relative to the map directory.
+	Path    string
+	Score   int
+	Reasons []string
+	// Tokens estimates the file's size in tokens.
+	Tokens int
+}
+
+// Pack is the bundle for one map.
+type Pack struct {
+	// Map is the map file as the caller named it.
+	Map       string
+	System    aimap.System
+	Focus     string
+	FocusKind string
+	// Entrypoints are the relevant entrypoints by protocol: all of them, or the
+	// ones the focus selects when it selects any.
+	Entrypoints  map[string][]string
+	Models       []string
+	Critical     []string
+	Ignore       []string
+	SafeWrite    []string
+	Ownership    aimap.Ownership
+	Dependencies aimap.Dependencies
+	Runtime      aimap.Runtime
+	// Files are the ranked files that fit the budget.
+	Files []File
+	// Omitted counts relevant files left out to stay within the budget.
+	Omitted int
+	Budget  int
+	// Used estimates the tokens of the rendered pack plus its listed files.
+	Used int
+}
+
+// Build assembles the pack for the map m governing mapDir. Files are the
+// map's source files (as sources.Walk sees them, so ignored paths never
+// appear), scored by the boundaries and focus that match them.
+func Build(mapLabel, mapDir string, m *aimap.Map, opt Options) (Pack, error) {
+	if opt.Budget <= 0 {
+		opt.Budget = DefaultBudget
+	}
+	inv, err := sources.Walk(mapDir, m)
+	if err != nil {
+		return Pack{}, err
+	}
+	flow := m.AIFlow()
+	p := Pack{
+		Map:          mapLabel,
+		System:       m.System,
+		Focus:        strings.TrimSpace(opt.Focus),
+		Entrypoints:  map[string][]string{},
+		Models:       m.Boundaries.Models,
+		Critical:     m.Boundaries.Critical,
+		Ignore:       flow.Ignore,
+		SafeWrite:    flow.SafeWrite,
+		Ownership:    m.Ownership,
+		Dependencies: m.Dependencies,
+		Runtime:      m.Runtime,
+		Budget:       opt.Budget,
+	}
+	p.FocusKind = focusKind(p.Focus, mapDir, m)
+
+	for _, proto := range m.Boundaries.Protocols() {
+		eps := m.Boundaries.Entrypoints[proto]
+		switch p.FocusKind {
+		case FocusProtocol:
+			if !strings.EqualFold(proto, p.Focus) {
+				continue
+			}
+		case FocusPath:
+			eps = overlapping(eps, p.Focus)
+		}
+		if len(eps) > 0 {
+			p.Entrypoints[proto] = eps
+		}
+	}
+	if p.FocusKind == FocusPath && len(p.Entrypoints) == 0 {
+		// The focus is outside every entrypoint; keep them all for orientation.
+		for _, proto := range m.Boundaries.Protocols() {
+			p.Entrypoints[proto] = m.Boundaries.Entrypoints[proto]
+		}
+	}
+
+	var ranked []File
+	for _, rel := range inv.Sources {
+		if sources.IsTest(rel) {
+			continue
+		}
+		f := score(rel, mapDir, m, p)
+		if f.Score == 0 {
+			continue
+		}
+		f.Tokens = fileTokens(filepath.Join(mapDir, filepath.FromSlash(rel)))
+		ranked = append(ranked, f)
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].Path < ranked[j].Path
+	})
+
+	// The pack's own text comes out of the budget first; files follow in rank
+	// order, skipping any that no longer fit.
+	p.Used = Tokens(len(p.Markdown()))
+	for _, f := range ranked {
+		line := Tokens(len(fileLine(len(p.Files)+1, f)))
+		if p.Used+line+f.Tokens > p.Budget {
+			p.Omitted++
+			continue
+		}
+		p.Used += line + f.Tokens
+		p.Files = append(p.Files, f)
+	}
+	return p, nil
+}
+
+// Tokens estimates the tokens in n bytes of text (about four bytes a token).
+func Tokens(n int) int {
+	return (n + 3) / 4
+}
+
+func fileTokens(p string) int {
+	st, err := os.Stat(p)
+	if err != nil {
+		return 0
+	}
+	return max(1, Tokens(int(st.Size())))
+}
+
+// focusKind decides what the focus names.
+func focusKind(focus, mapDir string, m *aimap.Map) string {
+	if focus == "" {
+		return FocusNone
+	}
+	for _, proto := range m.Boundaries.Protocols() {
+		if strings.EqualFold(proto, focus) {
+			return FocusProtocol
+		}
+	}
+	if _, err := os.Stat(filepath.Join(mapDir, filepath.FromSlash(aimap.StaticPrefix(focus)))); err == nil {
+		return FocusPath
+	}
+	return FocusKeyword
+}
+
+// overlapping returns the patterns that cover focus or lie beneath it.
+func overlapping(patterns []string, focus string) []string {
+	var out []string
+	for _, pat := range patterns {
+		if aimap.MatchPath(pat, focus) || aimap.MatchPath(focus, aimap.StaticPrefix(pat)) {
+			out = append(out, pat)
+		}
+	}
+	return out
+}
+
+// score rates a file: boundaries it falls under, and how the focus matches it.
+func score(rel, mapDir string, m *aimap.Map, p Pack) File {
+	f := File{Path: rel}
+	add := func(n int, reason string) {
+		f.Score += n
+		f.Reasons = append(f.Reasons, reason)
+	}
+	for _, proto := range m.Boundaries.Protocols() {
+		if matchAny(m.Boundaries.Entrypoints[proto], rel) {
+			add(3, "entrypoint ("+proto+")")
+			if p.FocusKind == FocusProtocol && strings.EqualFold(proto, p.Focus) {
+				add(10, "focus")
+			}
+		}
+	}
+	if matchAny(m.Boundaries.Models, rel) {
+		add(2, "model")
+	}
+	if matchAny(m.Boundaries.Critical, rel) {
+		add(2, "critical")
+	}
+	switch p.FocusKind {
+	case FocusPath:
+		if aimap.MatchPath(p.Focus, rel) {
+			add(10, "focus")
+		}
+	case FocusKeyword:
+		kw := strings.ToLower(p.Focus)
+		if strings.Contains(strings.ToLower(rel), kw) {
+			add(8, "focus (path)")
+		} else if containsFold(filepath.Join(mapDir, filepath.FromSlash(rel)), kw) {
+			add(4, "focus (content)")
+		}
+	}
+	// With a focus, files it does not reach only ride along on boundaries.
+	if p.FocusKind != FocusNone && !hasFocus(f.Reasons) {
+		f.Score = min(f.Score, 1)
+		if f.Score > 0 {
+			f.Reasons = append(f.Reasons, "outside focus")
+		}
+	}
+	return f
+}
+
+func hasFocus(reasons []string) bool {
+	for _, r := range reasons {
+		if strings.HasPrefix(r, "focus") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchAny(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		if strings.TrimSpace(p) != "" && !strings.Contains(p, "://") && aimap.MatchPath(p, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// containsFold reports whether the file at p contains the lower-case keyword.
+func containsFold(p, kw string) bool {
+	st, err := os.Stat(p)
+	if err != nil || st.Size() > maxScanBytes {
+		return false
+	}
+	b, err := os.ReadFile(p)
+	if err != nil {
+		return false
+	}
+	return bytes.Contains(bytes.ToLower(b), []byte(kw))
+}
+
+// Warnings are what an agent must respect: critical paths, ignored paths and
+// the narrower write permissions.
+func (p Pack) Warnings() []string {
+	var out []string
+	review := "its owners"
+	if p.Ownership.Team != "" {
+		review = p.Ownership.Team
+	}
+	for _, c := range p.Critical {
+		out = append(out, fmt.Sprintf("`%s` is critical: changes need review by %s", c, review))
+	}
+	for _, ig := range p.Ignore {
+		out = append(out, fmt.Sprintf("`%s` is ignored: do not read or edit it", ig))
+	}
+	for _, w := range p.SafeWrite {
+		out = append(out, fmt.Sprintf("`%s` is safe to write", w))
+	}
+	return out
+}
+
+// Markdown renders the pack.
+func (p Pack) Markdown() []byte {
+	var b bytes.Buffer
+	name := p.System.Name
+	if name == "" {
+		name = p.Map
+	}
+	fmt.Fprintf(&b, "# Context: %s\n\n", name)
+	var facts []string
+	for _, kv := range [][2]string{{"Type", p.System.Type}, {"Domain", p.System.Domain}, {"Language", p.System.Language}} {
+		if kv[1] != "" {
+			facts = append(facts, fmt.Sprintf("**%s:** %s", kv[0], kv[1]))
+		}
+	}
+	if len(facts) > 0 {
+		fmt.Fprintf(&b, "- %s\n", strings.Join(facts, " · "))
+	}
+	fmt.Fprintf(&b, "- **Map:** `%s` (paths below are relative to its directory)\n", p.Map)
+	if p.Focus != "" {
+		fmt.Fprintf(&b, "- **Focus:** %s (%s)\n", p.Focus, p.FocusKind)
+	}
+
+	section(&b, "Owners", []string{
+		item("Team", p.Ownership.Team),
+		item("Slack", p.Ownership.Slack),
+		item("Runbook", p.Ownership.Docs.Runbook),
+		item("ADRs", p.Ownership.Docs.ADR),
+		item("OpenAPI", p.Ownership.Docs.OpenAPI),
+	})
+
+	var bounds []string
+	protos := make([]string, 0, len(p.Entrypoints))
+	for proto := range p.Entrypoints {
+		protos = append(protos, proto)
+	}
+	sort.Strings(protos)
+	for _, proto := range protos {
+		bounds = append(bounds, item("Entrypoints ("+proto+")", paths(p.Entrypoints[proto])))
+	}
+	bounds = append(bounds, item("Models", paths(p.Models)))
+	section(&b, "Boundaries", bounds)
+
+	var warn []string
+	for _, w := range p.Warnings() {
+		warn = append(warn, "- "+w)
+	}
+	section(&b, "Warnings", warn)
+
+	var runtime []string
+	if p.Runtime.Environment != "" {
+		runtime = append(runtime, p.Runtime.Environment)
+	}
+	if p.Runtime.DeploysVia != "" {
+		runtime = append(runtime, "deploys via "+p.Runtime.DeploysVia)
+	}
+	section(&b, "Dependencies and runtime", []string{
+		item("Internal", strings.Join(p.Dependencies.Internal, ", ")),
+		item("External", strings.Join(p.Dependencies.External, ", ")),
+		item("Runtime", strings.Join(runtime, ", ")),
+		item("Config", paths(p.Runtime.ConfigPaths)),
+	})
+
+	b.WriteString("\n## Files\n\n")
+	if len(p.Files) == 0 && p.Omitted == 0 {
+		b.WriteString("No boundary or focus matches any source file.\n")
+	}
+	for i, f := range p.Files {
+		b.WriteString(fileLine(i+1, f))
+	}
+	if p.Omitted > 0 {
+		fmt.Fprintf(&b, "\n_%d more relevant file(s) left out to stay within ~%d tokens._\n", p.Omitted, p.Budget)
+	}
+	return b.Bytes()
+}
+
+func fileLine(rank int, f File) string {
+	return fmt.Sprintf("%d. `%s` (%s; ~%d tokens)\n", rank, f.Path, strings.Join(f.Reasons, ", "), f.Tokens)
+}
+
+// section writes a heading and its non-empty items; nothing when all are empty.
+func section(b *bytes.Buffer, title string, items []string) {
+	var lines []string
+	for _, it := range items {
+		if it != "" {
+			lines = append(lines, it)
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n## %s\n\n", title)
+	for _, l := range lines {
+		b.WriteString(l + "\n")
+	}
+}
+
+func item(label, value string) string {
+	if value == "" {
+		return ""
+	}
+	return fmt.Sprintf("- **%s:** %s", label, value)
+}
+
+func paths(ps []string) string {
+	quoted := make([]string, 0, len(ps))
+	for _, p := range ps {
+		quoted = append(quoted, "`"+p+"`")
+	}
+	return strings.Join(quoted, ", ")
+}
+
+// JSON returns the pack as a value for cjson.
+func (p Pack) JSON() map[string]any {
+	list := func(ss []string) []any {
+		out := make([]any, 0, len(ss))
+		for _, s := range ss {
+			out = append(out, s)
+		}
+		return out
+	}
+	entrypoints := map[string]any{}
+	for proto, eps := range p.Entrypoints {
+		entrypoints[proto] = list(eps)
+	}
+	files := make([]any, 0, len(p.Files))
+	for i, f := range p.Files {
+		files = append(files, map[string]any{
+			"rank":    i + 1,
+			"path":    f.Path,
+			"score":   f.Score,
+			"reasons": list(f.Reasons),
+			"tokens":  f.Tokens,
+		})
+	}
+	out := map[string]any{
+		"map": p.Map,
+		"system": map[string]any{
+			"name":     p.System.Name,
+			"type":     p.System.Type,
+			"domain":   p.System.Domain,
+			"language": p.System.Language,
+		},
+		"boundaries": map[string]any{
+			"entrypoints": entrypoints,
+			"models":      list(p.Models),
+			"critical":    list(p.Critical),
+		},
+		"warnings": list(p.Warnings()),
+		"owners": map[string]any{
+			"team":    p.Ownership.Team,
+			"slack":   p.Ownership.Slack,
+			"runbook": p.Ownership.Docs.Runbook,
+			"adr":     p.Ownership.Docs.ADR,
+			"openapi": p.Ownership.Docs.OpenAPI,
+		},
+		"dependencies": map[string]any{
+			"internal": list(p.Dependencies.Internal),
+			"external": list(p.Dependencies.External),
+		},
+		"runtime": map[string]any{
+			"environment":  p.Runtime.Environment,
+			"deploys_via":  p.Runtime.DeploysVia,
+			"config_paths": list(p.Runtime.ConfigPaths),
+		},
+		"files":   files,
+		"omitted": p.Omitted,
+		"budget":  p.Budget,
+		"used":    p.Used,
+	}
+	if p.Focus != "" {
+		out["focus"] = map[string]any{"value": p.Focus, "kind": p.FocusKind}
+	}
+	return out
+}
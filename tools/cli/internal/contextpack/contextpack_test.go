@@ -0,0 +1,86 @@
+package contextpack
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+func TestBuild(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"api/users.go":      "package api\n",
+		"api/orders.go":     "package api\n",
+		"model/user.go":     "package model\n",
+		"billing/charge.go": "package billing\n// refund users here\n" + strings.Repeat("x", 400),
+		"gen/users.go":      "package gen\n",
+		"api/users_test.go": "package api\n",
+	}
+	for name, body := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := aimap.Parse([]byte(`version: 1
+system:
+  name: shop
+boundaries:
+  entrypoints:
+    http: [api]
+  models: [model]
+  critical: [billing]
+ownership:
+  team: payments
+extensions:
+  ai-flow:
+    ignore: [gen]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := Build(".ai-map.yaml", dir, m, Options{Focus: "users"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range p.Files {
+		got = append(got, f.Path)
+	}
+	want := []string{"api/users.go", "billing/charge.go", "api/orders.go", "model/user.go"}
+	if strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("keyword focus: got %v, want %v", got, want)
+	}
+	md := p.Markdown()
+	if !bytes.Contains(md, []byte("`billing` is critical: changes need review by payments")) || !bytes.Contains(md, []byte("`gen` is ignored")) {
+		t.Errorf("warnings missing:\n%s", md)
+	}
+
+	again, err := Build(".ai-map.yaml", dir, m, Options{Focus: "users"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again.Markdown(), md) {
+		t.Error("same inputs gave different packs")
+	}
+
+	full, err := Build(".ai-map.yaml", dir, m, Options{Focus: "http"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	small, err := Build(".ai-map.yaml", dir, m, Options{Focus: "http", Budget: full.Used - 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if small.Omitted != 1 || len(small.Files) != 3 || small.Used > small.Budget {
+		t.Errorf("budget: got %d files, %d omitted, %d/%d tokens", len(small.Files), small.Omitted, small.Used, small.Budget)
+	}
+}
@@ -0,0 +1,174 @@
+// Package coverage measures how much of a repository's source code the maps'
+// search scope (spec section 4.1: entrypoints, models and critical paths) reaches.
+package coverage
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/discover"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/sources"
+)
+
+// Class is the boundary a source file falls under.
+type Class string
+
+// Classes, in the order a file is tested against them: critical paths first,
+// since they matter most for the scope report and often sit inside entrypoint
+// or model directories.
+const (
+	ClassCritical   Class = "critical"
+	ClassEntrypoint Class = "entrypoint"
+	ClassModel      Class = "model"
+	ClassUncovered  Class = "uncovered"
+)
+
+// File is one classified source file.
+type File struct {
+	// Path is relative to the walk root.
+	Path  string
+	Class Class
+	// Map is the governing map's path relative to the root; empty when no map governs the file.
+	Map string
+	// Pattern is the boundary pattern that matched; empty for uncovered files.
+	Pattern string
+}
+
+// Report is the classification of every source file under a root.
+type Report struct {
+	Files []File
+}
+
+// Stat counts files per class for a directory (or the whole report).
+type Stat struct {
+	Dir    string
+	Total  int
+	Counts map[Class]int
+}
+
+// Covered is the number of files in the agent search scope.
+func (s Stat) Covered() int { return s.Total - s.Counts[ClassUncovered] }
+
+// Percent is Covered as a percentage of Total (100 for an empty directory).
+func (s Stat) Percent() float64 {
+	if s.Total == 0 {
+		return 100
+	}
+	return 100 * float64(s.Covered()) / float64(s.Total)
+}
+
+// Compute walks root (honouring .gitignore) and classifies every non-test
+// source file against the deepest map governing it. Files under the map's
+// extensions.ai-flow.ignore paths are out of scope and not counted. maps must
+// be relative to root, as aimap.LoadTree returns them.
+func Compute(root string, maps []aimap.Located) (Report, error) {
+	sorted := append([]aimap.Located(nil), maps...)
+	// Deepest first, so the first map containing a file governs it.
+	sort.SliceStable(sorted, func(i, j int) bool { return depth(sorted[i].Dir) > depth(sorted[j].Dir) })
+
+	var r Report
+	err := discover.Walk(root, discover.Options{}, func(abs, rel string, d fs.DirEntry) error {
+		if sources.Language(rel) == "" || sources.IsTest(rel) {
+			return nil
+		}
+		f := File{Path: rel, Class: ClassUncovered}
+		for _, m := range sorted {
+			local, ok := within(rel, m.Dir)
+			if !ok {
+				continue
+			}
+			f.Map = m.Path
+			for _, p := range m.Map.AIFlow().Ignore {
+				if aimap.MatchPath(p, local) {
+					return nil
+				}
+			}
+			f.Class, f.Pattern = Classify(m.Map, local)
+			break
+		}
+		r.Files = append(r.Files, f)
+		return nil
+	})
+	if err != nil {
+		return Report{}, err
+	}
+	return r, nil
+}
+
+// Classify returns the class of rel (relative to the map's directory) and the
+// pattern that put it there.
+func Classify(m *aimap.Map, rel string) (Class, string) {
+	for _, c := range []struct {
+		class    Class
+		patterns []string
+	}{
+		{ClassCritical, m.Boundaries.Critical},
+		{ClassEntrypoint, m.Boundaries.AllEntrypoints()},
+		{ClassModel, m.Boundaries.Models},
+	} {
+		for _, p := range c.patterns {
+			if strings.TrimSpace(p) != "" && !strings.Contains(p, "://") && aimap.MatchPath(p, rel) {
+				return c.class, p
+			}
+		}
+	}
+	return ClassUncovered, ""
+}
+
+// Total sums the whole report.
+func (r Report) Total() Stat {
+	s := Stat{Dir: ".", Counts: map[Class]int{}}
+	for _, f := range r.Files {
+		s.Total++
+		s.Counts[f.Class]++
+	}
+	return s
+}
+
+// Dirs sums the report per directory, cut to at most depth segments ("." holds
+// files at the root), sorted by directory.
+func (r Report) Dirs(depth int) []Stat {
+	byDir := map[string]*Stat{}
+	var dirs []string
+	for _, f := range r.Files {
+		dir := path.Dir(f.Path)
+		if segs := strings.Split(dir, "/"); dir != "." && len(segs) > depth {
+			dir = strings.Join(segs[:depth], "/")
+		}
+		s, ok := byDir[dir]
+		if !ok {
+			s = &Stat{Dir: dir, Counts: map[Class]int{}}
+			byDir[dir] = s
+			dirs = append(dirs, dir)
+		}
+		s.Total++
+		s.Counts[f.Class]++
+	}
+	sort.Strings(dirs)
+	out := make([]Stat, 0, len(dirs))
+	for _, d := range dirs {
+		out = append(out, *byDir[d])
+	}
+	return out
+}
+
+// within returns rel relative to dir when dir contains it.
+func within(rel, dir string) (string, bool) {
+	if dir == "." || dir == "" {
+		return rel, true
+	}
+	if strings.HasPrefix(rel, dir+"/") {
+		return strings.TrimPrefix(rel, dir+"/"), true
+	}
+	return "", false
+}
+
+func depth(dir string) int {
+	if dir == "." || dir == "" {
+		return 0
+	}
+	return strings.Count(dir, "/") + 1
+}
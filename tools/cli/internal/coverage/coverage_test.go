@@ -0,0 +1,68 @@
+package coverage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/input"
+)
+
+func TestCompute(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".ai-map.yaml":                "version: 1\nsystem:\n  name: root\nboundaries:\n  critical: [lib/auth]\n",
+		"lib/auth/token.go":           "",
+		"lib/util.go":                 "",
+		"lib/util_test.go":            "",
+		"svc/users/.ai-map.yaml":      "version: 1\nsystem:\n  name: users\nboundaries:\n  entrypoints:\n    http: [api]\n  models: [model/**]\n  critical: [api/pay.go]\nextensions:\n  ai-flow:\n    ignore: [gen]\n",
+		"svc/users/api/handler.go":    "",
+		"svc/users/api/pay.go":        "",
+		"svc/users/model/user.go":     "",
+		"svc/users/internal/store.go": "",
+		"svc/users/gen/client.go":     "",
+		"svc/users/README.md":         "",
+	}
+	for name, body := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	maps, err := aimap.LoadTree(root, input.MaxYAMLBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rep, err := Compute(root, maps)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]Class{
+		"lib/auth/token.go":           ClassCritical,
+		"lib/util.go":                 ClassUncovered,
+		"svc/users/api/handler.go":    ClassEntrypoint,
+		"svc/users/api/pay.go":        ClassCritical, // critical wins over the entrypoint directory
+		"svc/users/internal/store.go": ClassUncovered,
+		"svc/users/model/user.go":     ClassModel,
+	}
+	if len(rep.Files) != len(want) {
+		t.Fatalf("got %d files, want %d: %+v", len(rep.Files), len(want), rep.Files)
+	}
+	for _, f := range rep.Files {
+		if want[f.Path] != f.Class {
+			t.Errorf("%s: got %s, want %s", f.Path, f.Class, want[f.Path])
+		}
+	}
+	if got := rep.Total().Percent(); got != 200.0/3 {
+		t.Errorf("total: got %.1f%%, want 66.7%%", got)
+	}
+	dirs := rep.Dirs(2)
+	if len(dirs) != 3 || dirs[0].Dir != "lib" || dirs[1].Dir != "lib/auth" || dirs[2].Dir != "svc/users" || dirs[2].Covered() != 3 {
+		t.Errorf("dirs: got %+v", dirs)
+	}
+}
@@ -0,0 +1,183 @@
+package detect
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ComposeFiles are the compose file names looked for in a map's directory.
+var ComposeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// images maps well-known image names (without registry, tag or digest) to
+// canonical dependencies.external identifiers.
+var images = map[string]string{
+	"postgres":                       "postgres",
+	"postgis/postgis":                "postgres",
+	"bitnami/postgresql":             "postgres",
+	"mysql":                          "mysql",
+	"bitnami/mysql":                  "mysql",
+	"mariadb":                        "mariadb",
+	"mongo":                          "mongodb",
+	"bitnami/mongodb":                "mongodb",
+	"redis":                          "redis",
+	"bitnami/redis":                  "redis",
+	"redis/redis-stack":              "redis",
+	"redis/redis-stack-server":       "redis",
+	"valkey/valkey":                  "redis",
+	"memcached":                      "memcached",
+	"rabbitmq":                       "rabbitmq",
+	"bitnami/rabbitmq":               "rabbitmq",
+	"confluentinc/cp-kafka":          "kafka",
+	"bitnami/kafka":                  "kafka",
+	"apache/kafka":                   "kafka",
+	"redpandadata/redpanda":          "kafka",
+	"elasticsearch":                  "elasticsearch",
+	"elasticsearch/elasticsearch":    "elasticsearch",
+	"opensearchproject/opensearch":   "opensearch",
+	"nats":                           "nats",
+	"localstack/localstack":          "aws",
+	"amazon/dynamodb-local":          "aws.dynamodb",
+	"minio/minio":                    "s3",
+	"softwaremill/elasticmq":         "aws.sqs",
+	"mcr.microsoft.com/mssql/server": "mssql",
+	"mcr.microsoft.com/azure-storage/azurite": "azure.storage",
+	"clickhouse/clickhouse-server":            "clickhouse",
+	"cassandra":                               "cassandra",
+	"neo4j":                                   "neo4j",
+	"mailhog/mailhog":                         "smtp",
+	"axllent/mailpit":                         "smtp",
+	"stripe/stripe-mock":                      "stripe",
+	"temporalio/auto-setup":                   "temporal",
+	"vault":                                   "vault",
+	"hashicorp/vault":                         "vault",
+	"consul":                                  "consul",
+	"hashicorp/consul":                        "consul",
+	"jaegertracing/all-in-one":                "jaeger",
+	"otel/opentelemetry-collector":            "opentelemetry",
+	"otel/opentelemetry-collector-contrib":    "opentelemetry",
+}
+
+// serviceAliases are other spellings of canonical identifiers seen in maps.
+var serviceAliases = map[string]string{
+	"postgresql": "postgres",
+	"mongo":      "mongodb",
+	"localstack": "aws",
+	"minio":      "s3",
+	"elastic":    "elasticsearch",
+}
+
+// registryPrefixes are dropped before looking an image up.
+var registryPrefixes = []string{"docker.io/library/", "docker.io/", "index.docker.io/", "public.ecr.aws/docker/library/", "docker.elastic.co/", "quay.io/"}
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image   string    `yaml:"image"`
+	Build   yaml.Node `yaml:"build"`
+	EnvFile yaml.Node `yaml:"env_file"`
+}
+
+// Compose reads the compose files in dir. Well-known images become external
+// dependencies, env files become config paths, and a service built from the
+// directory suggests a container runtime.
+func Compose(dir string) ([]Finding, error) {
+	var out []Finding
+	for _, name := range ComposeFiles {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var cf composeFile
+		if err := yaml.Unmarshal(b, &cf); err != nil {
+			return nil, fmt.Errorf("%s: YAML parse error: %w", name, err)
+		}
+
+		services := make([]string, 0, len(cf.Services))
+		for s := range cf.Services {
+			services = append(services, s)
+		}
+		sort.Strings(services)
+		for _, s := range services {
+			svc := cf.Services[s]
+			if svc.Image != "" {
+				if id, ok := ImageService(svc.Image); ok {
+					out = append(out, Finding{DetectorCompose, FieldExternal, id, name, "image " + svc.Image})
+				}
+			}
+			if !svc.Build.IsZero() {
+				out = append(out, Finding{DetectorCompose, FieldEnvironment, "container", name, "service " + s + " is built here"})
+			}
+			for _, env := range envFiles(svc.EnvFile) {
+				p := path.Clean(filepath.ToSlash(env))
+				if strings.HasPrefix(p, "../") || path.IsAbs(p) {
+					continue
+				}
+				out = append(out, Finding{DetectorCompose, FieldConfigPaths, p, name, "env_file of service " + s})
+			}
+		}
+	}
+	return out, nil
+}
+
+// ImageService maps an image reference to a canonical service identifier.
+func ImageService(image string) (string, bool) {
+	ref := strings.ToLower(strings.TrimSpace(image))
+	if i := strings.Index(ref, "@"); i >= 0 {
+		ref = ref[:i]
+	}
+	// A tag follows the last colon after the last slash (a colon before it is a registry port).
+	if i := strings.LastIndex(ref, ":"); i > strings.LastIndex(ref, "/") {
+		ref = ref[:i]
+	}
+	for _, p := range registryPrefixes {
+		ref = strings.TrimPrefix(ref, p)
+	}
+	if id, ok := images[ref]; ok {
+		return id, true
+	}
+	// Mirrors and private registries: fall back to the last path segments.
+	segs := strings.Split(ref, "/")
+	for i := 1; i < len(segs); i++ {
+		if id, ok := images[strings.Join(segs[i:], "/")]; ok {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// envFiles reads env_file, which may be a string, a list of strings, or a list
+// of {path: ...} objects.
+func envFiles(n yaml.Node) []string {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return []string{n.Value}
+	case yaml.SequenceNode:
+		var out []string
+		for _, it := range n.Content {
+			switch it.Kind {
+			case yaml.ScalarNode:
+				out = append(out, it.Value)
+			case yaml.MappingNode:
+				var obj struct {
+					Path string `yaml:"path"`
+				}
+				if it.Decode(&obj) == nil && obj.Path != "" {
+					out = append(out, obj.Path)
+				}
+			}
+		}
+		return out
+	}
+	return nil
+}
@@ -0,0 +1,361 @@
+package detect
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Deploy mechanisms, the values of runtime.deploys_via.
+const (
+	DeploysGitHubActions = "github-actions"
+	DeploysCDK           = "cdk"
+	DeploysTerraform     = "terraform"
+	DeploysManual        = "manual"
+	DeploysOther         = "other"
+)
+
+// tools are deploy commands that name the mechanism more precisely than the
+// pipeline running them.
+var tools = []struct {
+	re    *regexp.Regexp
+	value string
+	label string
+}{
+	{regexp.MustCompile(`\bcdk\s+deploy\b`), DeploysCDK, "cdk deploy"},
+	{regexp.MustCompile(`\bterraform\s+apply\b`), DeploysTerraform, "terraform apply"},
+	{regexp.MustCompile(`\b(serverless|sls)\s+deploy\b`), DeploysOther, "serverless deploy"},
+}
+
+// deployCommands are run commands that deploy, with the target they deploy to.
+var deployCommands = []struct {
+	re     *regexp.Regexp
+	target string
+}{
+	{regexp.MustCompile(`\bcdk\s+deploy\b`), "aws"},
+	{regexp.MustCompile(`\b(serverless|sls)\s+deploy\b`), ""},
+	{regexp.MustCompile(`\bterraform\s+apply\b`), ""},
+	{regexp.MustCompile(`\baws\s+(ecs\s+update-service|lambda\s+update-function-code|s3\s+sync|cloudformation\s+deploy|deploy\s+)`), "aws"},
+	{regexp.MustCompile(`\bsam\s+deploy\b`), "aws"},
+	{regexp.MustCompile(`\bgcloud\s+(run|app|functions)\s+deploy\b`), "gcp"},
+	{regexp.MustCompile(`\baz\s+(webapp|functionapp|containerapp)\b`), "azure"},
+	{regexp.MustCompile(`\b(kubectl\s+apply|helm\s+(upgrade|install))\b`), "kubernetes"},
+	{regexp.MustCompile(`\bflyctl\s+deploy\b`), "fly.io"},
+	{regexp.MustCompile(`\bvercel\b.*(--prod|\bdeploy\b)`), "vercel"},
+	{regexp.MustCompile(`\bnetlify\s+deploy\b`), "netlify"},
+	{regexp.MustCompile(`\bwrangler\s+(deploy|publish)\b`), "cloudflare"},
+}
+
+// deployActions are GitHub Actions (owner/ or owner/name prefixes of `uses`)
+// that deploy or authenticate against a deploy target.
+var deployActions = []struct {
+	prefix string
+	target string
+}{
+	{"aws-actions/", "aws"},
+	{"google-github-actions/", "gcp"},
+	{"azure/", "azure"},
+	{"actions/deploy-pages", "github-pages"},
+	{"peaceiris/actions-gh-pages", "github-pages"},
+	{"superfly/", "fly.io"},
+	{"amondnet/vercel-action", "vercel"},
+	{"nwtgck/actions-netlify", "netlify"},
+	{"cloudflare/wrangler-action", "cloudflare"},
+	{"akhileshns/heroku-deploy", "heroku"},
+	{"azure/k8s-deploy", "kubernetes"},
+}
+
+// terraformProviders maps provider names to deploy targets.
+var terraformProviders = map[string]string{
+	"aws":        "aws",
+	"google":     "gcp",
+	"azurerm":    "azure",
+	"kubernetes": "kubernetes",
+	"helm":       "kubernetes",
+	"cloudflare": "cloudflare",
+	"vercel":     "vercel",
+	"heroku":     "heroku",
+}
+
+var terraformProvider = regexp.MustCompile(`(?m)^\s*provider\s+"([A-Za-z0-9_-]+)"`)
+
+// job is a CI job reduced to what deploy detection needs.
+type job struct {
+	name        string
+	environment string
+	stage       string
+	uses        []string
+	run         string
+}
+
+// Deploy infers runtime.deploys_via from CI workflows (.github/workflows,
+// .gitlab-ci.yml) at the repository root and from cdk.json, serverless.yml and
+// Terraform files in dir. Pipelines come first, then the tools they or the
+// directory use; a map may declare any of them. Workflows outside dir only
+// count when they mention dir's path. Deploy targets are given in Detail.
+func Deploy(dir string) ([]Finding, error) {
+	var out []Finding
+	root, rel := repoRoot(dir)
+
+	workflows, _ := filepath.Glob(filepath.Join(root, ".github", "workflows", "*.y*ml"))
+	sort.Strings(workflows)
+	for _, wf := range workflows {
+		found, err := deployWorkflow(dir, wf, rel, DeploysGitHubActions, githubJobs)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, found...)
+	}
+	found, err := deployWorkflow(dir, filepath.Join(root, ".gitlab-ci.yml"), rel, DeploysOther, gitlabJobs)
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, found...)
+
+	if _, err := os.Stat(filepath.Join(dir, "cdk.json")); err == nil {
+		out = append(out, Finding{DetectorDeploy, FieldDeploysVia, DeploysCDK, "cdk.json", "CDK app; targets aws"})
+	}
+	tf, err := terraformFinding(dir)
+	if err != nil {
+		return nil, err
+	}
+	out = append(out, tf...)
+	for _, name := range []string{"serverless.yml", "serverless.yaml"} {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var sls struct {
+			Provider struct {
+				Name string `yaml:"name"`
+			} `yaml:"provider"`
+		}
+		if err := yaml.Unmarshal(b, &sls); err != nil {
+			return nil, fmt.Errorf("%s: YAML parse error: %w", name, err)
+		}
+		detail := "Serverless Framework service"
+		if sls.Provider.Name != "" {
+			detail += "; targets " + sls.Provider.Name
+		}
+		out = append(out, Finding{DetectorDeploy, FieldDeploysVia, DeploysOther, name, detail})
+	}
+	return out, nil
+}
+
+// repoRoot returns the nearest ancestor of dir containing .git (dir itself when
+// there is none) and dir's slash path relative to it.
+func repoRoot(dir string) (root, rel string) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return dir, "."
+	}
+	for d := abs; ; d = filepath.Dir(d) {
+		if _, err := os.Stat(filepath.Join(d, ".git")); err == nil {
+			r, err := filepath.Rel(d, abs)
+			if err != nil {
+				return dir, "."
+			}
+			return d, filepath.ToSlash(r)
+		}
+		if filepath.Dir(d) == d {
+			return dir, "."
+		}
+	}
+}
+
+// deployWorkflow reports the deploy jobs of the CI file at p as the pipeline
+// mechanism, plus the tool each job runs.
+func deployWorkflow(dir, p, rel, pipeline string, parse func([]byte) ([]job, error)) ([]Finding, error) {
+	b, err := os.ReadFile(p)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	source := p
+	if abs, err := filepath.Abs(dir); err == nil {
+		if r, err := filepath.Rel(abs, p); err == nil {
+			source = filepath.ToSlash(r)
+		}
+	}
+	// A repository-wide workflow deploys a nested system only if it names it.
+	if rel != "." && !strings.Contains(string(b), rel) {
+		return nil, nil
+	}
+	jobs, err := parse(b)
+	if err != nil {
+		return nil, fmt.Errorf("%s: YAML parse error: %w", source, err)
+	}
+
+	var out []Finding
+	for _, j := range jobs {
+		targets, deploys := jobTargets(j)
+		if !deploys && j.environment == "" && j.stage != "deploy" {
+			continue
+		}
+		detail := "job " + j.name + " deploys"
+		if len(targets) > 0 {
+			detail += " to " + strings.Join(targets, ", ")
+		}
+		if j.environment != "" {
+			detail += " (environment " + j.environment + ")"
+		}
+		out = append(out, Finding{DetectorDeploy, FieldDeploysVia, pipeline, source, detail})
+		for _, t := range tools {
+			if t.re.MatchString(j.run) {
+				out = append(out, Finding{DetectorDeploy, FieldDeploysVia, t.value, source, "job " + j.name + " runs " + t.label})
+			}
+		}
+	}
+	return out, nil
+}
+
+// jobTargets returns the distinct deploy targets a job's actions and commands
+// point at, sorted, and whether any of them deploys.
+func jobTargets(j job) ([]string, bool) {
+	seen := map[string]bool{}
+	var out []string
+	add := func(t string) {
+		if t != "" && !seen[t] {
+			seen[t] = true
+			out = append(out, t)
+		}
+	}
+	deploys := false
+	for _, u := range j.uses {
+		u = strings.ToLower(u)
+		for _, a := range deployActions {
+			if strings.HasPrefix(u, a.prefix) {
+				deploys = true
+				add(a.target)
+			}
+		}
+	}
+	for _, c := range deployCommands {
+		if c.re.MatchString(j.run) {
+			deploys = true
+			add(c.target)
+		}
+	}
+	sort.Strings(out)
+	return out, deploys
+}
+
+func githubJobs(b []byte) ([]job, error) {
+	var wf struct {
+		Jobs map[string]struct {
+			Environment yaml.Node `yaml:"environment"`
+			Steps       []struct {
+				Uses string `yaml:"uses"`
+				Run  string `yaml:"run"`
+			} `yaml:"steps"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(b, &wf); err != nil {
+		return nil, err
+	}
+	var out []job
+	for name, j := range wf.Jobs {
+		jb := job{name: name, environment: environmentName(j.Environment)}
+		var run []string
+		for _, s := range j.Steps {
+			if s.Uses != "" {
+				jb.uses = append(jb.uses, s.Uses)
+			}
+			run = append(run, s.Run)
+		}
+		jb.run = strings.Join(run, "\n")
+		out = append(out, jb)
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].name < out[b].name })
+	return out, nil
+}
+
+func gitlabJobs(b []byte) ([]job, error) {
+	var top map[string]yaml.Node
+	if err := yaml.Unmarshal(b, &top); err != nil {
+		return nil, err
+	}
+	var out []job
+	for name, n := range top {
+		var j struct {
+			Stage       string    `yaml:"stage"`
+			Environment yaml.Node `yaml:"environment"`
+			Script      yaml.Node `yaml:"script"`
+		}
+		// Jobs are the mappings with a script; everything else is configuration.
+		if n.Kind != yaml.MappingNode || strings.HasPrefix(name, ".") || n.Decode(&j) != nil || j.Script.IsZero() {
+			continue
+		}
+		var script []string
+		if j.Script.Kind == yaml.ScalarNode {
+			script = []string{j.Script.Value}
+		} else {
+			_ = j.Script.Decode(&script)
+		}
+		out = append(out, job{name: name, stage: j.Stage, environment: environmentName(j.Environment), run: strings.Join(script, "\n")})
+	}
+	sort.Slice(out, func(a, b int) bool { return out[a].name < out[b].name })
+	return out, nil
+}
+
+// environmentName reads a job environment given as a name or {name: ...}.
+func environmentName(n yaml.Node) string {
+	switch n.Kind {
+	case yaml.ScalarNode:
+		return n.Value
+	case yaml.MappingNode:
+		var env struct {
+			Name string `yaml:"name"`
+		}
+		if n.Decode(&env) == nil {
+			return env.Name
+		}
+	}
+	return ""
+}
+
+// terraformFinding reports Terraform configuration in dir or, failing that, in
+// its immediate subdirectories (terraform/, infra/, ...), with its providers as targets.
+func terraformFinding(dir string) ([]Finding, error) {
+	files, _ := filepath.Glob(filepath.Join(dir, "*.tf"))
+	source := "*.tf"
+	if len(files) == 0 {
+		nested, _ := filepath.Glob(filepath.Join(dir, "*", "*.tf"))
+		if len(nested) == 0 {
+			return nil, nil
+		}
+		files = nested
+		source = filepath.Base(filepath.Dir(nested[0])) + "/*.tf"
+	}
+	var targets []string
+	seen := map[string]bool{}
+	for _, f := range files {
+		b, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range terraformProvider.FindAllStringSubmatch(string(b), -1) {
+			t, ok := terraformProviders[strings.ToLower(m[1])]
+			if ok && !seen[t] {
+				seen[t] = true
+				targets = append(targets, t)
+			}
+		}
+	}
+	sort.Strings(targets)
+	detail := fmt.Sprintf("%d Terraform file(s)", len(files))
+	if len(targets) > 0 {
+		detail += "; targets " + strings.Join(targets, ", ")
+	}
+	return []Finding{{DetectorDeploy, FieldDeploysVia, DeploysTerraform, source, detail}}, nil
+}
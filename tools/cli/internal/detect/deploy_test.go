@@ -0,0 +1,82 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+func TestDeploy(t *testing.T) {
+	root := t.TempDir()
+	dir := filepath.Join(root, "services", "orders")
+	files := map[string]string{
+		".git/HEAD":                "ref: refs/heads/main\n",
+		".github/workflows/ci.yml": "jobs:\n  test:\n    steps:\n      - run: go test ./...\n",
+		".github/workflows/deploy.yml": "on:\n  push:\n    paths: [services/orders/**]\njobs:\n  deploy:\n    environment: production\n    steps:\n" +
+			"      - uses: aws-actions/configure-aws-credentials@v4\n      - run: npx cdk deploy --require-approval never\n        working-directory: services/orders\n",
+		".github/workflows/web.yml": "jobs:\n  deploy:\n    steps:\n      - run: vercel deploy --prod\n        working-directory: web\n",
+		"services/orders/cdk.json":  "{}\n",
+	}
+	for name, body := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	found, err := Deploy(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range found {
+		got = append(got, f.Value+" "+f.Source+": "+f.Detail)
+	}
+	want := []string{
+		"github-actions ../../.github/workflows/deploy.yml: job deploy deploys to aws (environment production)",
+		"cdk ../../.github/workflows/deploy.yml: job deploy runs cdk deploy",
+		"cdk cdk.json: CDK app; targets aws",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	r := Report{Findings: found}
+	for _, declared := range []string{"", "github-actions", "cdk"} {
+		m := &aimap.Map{}
+		m.Runtime.DeploysVia = declared
+		if mm := Compare(m, r); len(mm) != 0 {
+			t.Errorf("deploys_via %q: unexpected mismatches %v", declared, mm)
+		}
+	}
+	m := &aimap.Map{}
+	m.Runtime.DeploysVia = "manual"
+	mm := Compare(m, r)
+	if len(mm) != 1 || mm[0].Detector != DetectorDeploy || !strings.Contains(mm[0].Message, "set to manual, but ../../.github/workflows/deploy.yml suggests github-actions or cdk") {
+		t.Fatalf("manual: got %v", mm)
+	}
+}
+
+func TestDeployTerraform(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "infra"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	tf := "provider \"google\" {\n  project = \"x\"\n}\nprovider \"kubernetes\" {}\n"
+	if err := os.WriteFile(filepath.Join(dir, "infra", "main.tf"), []byte(tf), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	found, err := Deploy(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(found) != 1 || found[0].Value != DeploysTerraform || found[0].Source != "infra/*.tf" || found[0].Detail != "1 Terraform file(s); targets gcp, kubernetes" {
+		t.Fatalf("got %v", found)
+	}
+}
@@ -0,0 +1,325 @@
+// Package detect derives map fields from files that already describe a system
+// (compose files, module manifests, CI configuration) and reports where a map
+// disagrees with them.
+package detect
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"gopkg.in/yaml.v3"
+)
+
+// Detector names, as reported in Finding.Detector.
+const (
+	DetectorCompose   = "compose"
+	DetectorManifests = "manifests"
+	DetectorDeploy    = "deploy"
+)
+
+// Fields detectors propose values for.
+const (
+	FieldInternal    = "dependencies.internal"
+	FieldExternal    = "dependencies.external"
+	FieldEnvironment = "runtime.environment"
+	FieldConfigPaths = "runtime.config_paths"
+	FieldDeploysVia  = "runtime.deploys_via"
+)
+
+// listFields hold several values; every other field holds one.
+var listFields = map[string]bool{FieldInternal: true, FieldExternal: true, FieldConfigPaths: true}
+
+// Finding is one value a detector proposes for a map field.
+type Finding struct {
+	Detector string
+	// Field is the dotted map field, e.g. dependencies.external.
+	Field string
+	Value string
+	// Source is the file the value was derived from, relative to the map directory.
+	Source string
+	// Detail explains the derivation, e.g. "image postgres:16".
+	Detail string
+}
+
+// Report is everything the detectors found for one directory.
+type Report struct {
+	Findings []Finding
+	// Manifests are the module manifests read for internal dependencies; empty
+	// when no InternalPrefixes were configured or none exist.
+	Manifests []string
+}
+
+// Options tunes Detect.
+type Options struct {
+	// InternalPrefixes select the manifest dependencies that are internal, e.g.
+	// "github.com/ourorg/*" or "@ourorg/*" (see Prefix). Without any, internal
+	// dependencies are not detected.
+	InternalPrefixes []string
+	// Systems are the known system.name values; a dependency whose name matches
+	// one (ignoring case and -, _ and .) is reported under that name.
+	Systems []string
+	// Only limits Detect to the named detectors (Detector* constants); empty runs all.
+	Only []string
+}
+
+func (o Options) runs(detector string) bool {
+	if len(o.Only) == 0 {
+		return true
+	}
+	for _, d := range o.Only {
+		if d == detector {
+			return true
+		}
+	}
+	return false
+}
+
+// Detect runs every detector against the system rooted at dir.
+func Detect(dir string, opt Options) (Report, error) {
+	var r Report
+	for _, d := range []struct {
+		name string
+		run  func(string) ([]Finding, error)
+	}{
+		{DetectorCompose, Compose},
+		{DetectorDeploy, Deploy},
+	} {
+		if !opt.runs(d.name) {
+			continue
+		}
+		found, err := d.run(dir)
+		if err != nil {
+			return Report{}, err
+		}
+		r.Findings = append(r.Findings, found...)
+	}
+
+	if len(opt.InternalPrefixes) > 0 && opt.runs(DetectorManifests) {
+		mods, files, err := Manifests(dir)
+		if err != nil {
+			return Report{}, err
+		}
+		r.Manifests = files
+		r.Findings = append(r.Findings, internalFindings(mods, opt)...)
+	}
+	return r, nil
+}
+
+// internalFindings maps modules under the org prefixes to system names.
+func internalFindings(mods []Module, opt Options) []Finding {
+	known := map[string]string{}
+	for _, s := range opt.Systems {
+		known[foldName(s)] = s
+	}
+	var out []Finding
+	for _, m := range mods {
+		for _, p := range opt.InternalPrefixes {
+			name, ok := Prefix(p).Match(m.Path)
+			if !ok {
+				continue
+			}
+			detail := "requires " + m.Path
+			if sys, ok := known[foldName(name)]; ok {
+				name = sys
+			} else if len(known) > 0 {
+				detail += "; no map declares this system"
+			}
+			out = append(out, Finding{DetectorManifests, FieldInternal, name, m.Source, detail})
+			break
+		}
+	}
+	return out
+}
+
+// foldName folds case and separators for comparing system and package names.
+func foldName(s string) string {
+	return strings.NewReplacer("-", "", "_", "", ".", "").Replace(strings.ToLower(s))
+}
+
+// Values returns the distinct values found for field, in order of discovery.
+func (r Report) Values(field string) []Finding {
+	var out []Finding
+	seen := map[string]bool{}
+	for _, f := range r.Findings {
+		if f.Field == field && !seen[f.Value] {
+			seen[f.Value] = true
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+// Fields returns the fields with findings, sorted.
+func (r Report) Fields() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, f := range r.Findings {
+		if !seen[f.Field] {
+			seen[f.Field] = true
+			out = append(out, f.Field)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// IsList reports whether field holds a list of values.
+func IsList(field string) bool { return listFields[field] }
+
+// Mismatch is a disagreement between a map and a finding.
+type Mismatch struct {
+	Detector string
+	Field    string
+	Message  string
+}
+
+// Compare reports findings the map does not reflect: list values it does not
+// declare, and single values it leaves unset or sets differently.
+func Compare(m *aimap.Map, r Report) []Mismatch {
+	var out []Mismatch
+	for _, field := range r.Fields() {
+		found := r.Values(field)
+		switch field {
+		case FieldInternal:
+			for _, f := range found {
+				if !declaresName(m.Dependencies.Internal, f.Value) {
+					out = append(out, Mismatch{f.Detector, field, fmt.Sprintf("%s %s but %s does not list %s", f.Source, f.Detail, field, f.Value)})
+				}
+			}
+		case FieldExternal:
+			for _, f := range found {
+				if !coversService(m.Dependencies.External, f.Value) {
+					out = append(out, Mismatch{f.Detector, field, fmt.Sprintf("%s uses %s (%s) but %s does not list it", f.Source, f.Value, f.Detail, field)})
+				}
+			}
+		case FieldConfigPaths:
+			for _, f := range found {
+				if !coversPath(m.Runtime.ConfigPaths, f.Value) {
+					out = append(out, Mismatch{f.Detector, field, fmt.Sprintf("%s loads %s but %s does not cover it", f.Source, f.Value, field)})
+				}
+			}
+		case FieldEnvironment:
+			f := found[0]
+			switch declared := m.Runtime.Environment; {
+			case declared == "":
+				out = append(out, Mismatch{f.Detector, field, fmt.Sprintf("not set, but %s suggests %s (%s)", f.Source, f.Value, f.Detail)})
+			case declared != f.Value:
+				out = append(out, Mismatch{f.Detector, field, fmt.Sprintf("set to %s, but %s suggests %s (%s)", declared, f.Source, f.Value, f.Detail)})
+			}
+		case FieldDeploysVia:
+			// Every mechanism found is a fair answer (a workflow running cdk deploy is
+			// both github-actions and cdk); only a declared value outside them
+			// contradicts the repository. Unset is left to the fragment's suggestion.
+			declared := m.Runtime.DeploysVia
+			var values []string
+			for _, f := range found {
+				values = append(values, f.Value)
+			}
+			if declared != "" && !declaresName(values, declared) {
+				f := found[0]
+				out = append(out, Mismatch{f.Detector, field, fmt.Sprintf("set to %s, but %s suggests %s (%s)", declared, f.Source, strings.Join(values, " or "), f.Detail)})
+			}
+		}
+	}
+
+	// Declared internal dependencies no manifest requires. Only meaningful once
+	// manifests were read; a system may also depend on another over the network.
+	if len(r.Manifests) > 0 {
+		var used []string
+		for _, f := range r.Values(FieldInternal) {
+			used = append(used, f.Value)
+		}
+		for _, d := range m.Dependencies.Internal {
+			if !declaresName(used, d) {
+				out = append(out, Mismatch{DetectorManifests, FieldInternal, fmt.Sprintf("lists %s but no manifest (%s) requires it", d, strings.Join(r.Manifests, ", "))})
+			}
+		}
+	}
+	return out
+}
+
+func declaresName(names []string, name string) bool {
+	for _, n := range names {
+		if foldName(n) == foldName(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// coversService reports whether a declared external dependency names service,
+// allowing qualified forms such as "redis.cache" for "redis".
+func coversService(declared []string, service string) bool {
+	for _, d := range declared {
+		d = strings.ToLower(strings.TrimSpace(d))
+		if d == service || strings.HasPrefix(d, service+".") || strings.HasPrefix(service, d+".") {
+			return true
+		}
+		if alias, ok := serviceAliases[d]; ok && alias == service {
+			return true
+		}
+	}
+	return false
+}
+
+func coversPath(patterns []string, p string) bool {
+	for _, pat := range patterns {
+		if aimap.MatchPath(pat, p) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fragment renders the findings as a draft map fragment, each value commented
+// with where it came from.
+func (r Report) Fragment() ([]byte, error) {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	for _, field := range r.Fields() {
+		section, key, _ := strings.Cut(field, ".")
+		parent := child(root, section)
+		found := r.Values(field)
+		var v *yaml.Node
+		if IsList(field) {
+			v = &yaml.Node{Kind: yaml.SequenceNode}
+			for _, f := range found {
+				v.Content = append(v.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: f.Value, LineComment: comment(f)})
+			}
+		} else {
+			v = &yaml.Node{Kind: yaml.ScalarNode, Value: found[0].Value, LineComment: comment(found[0])}
+		}
+		parent.Content = append(parent.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, v)
+	}
+	if len(root.Content) == 0 {
+		return nil, nil
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// child returns the mapping under key in m, appending it if missing.
+func child(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	c := &yaml.Node{Kind: yaml.MappingNode}
+	m.Content = append(m.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, c)
+	return c
+}
+
+func comment(f Finding) string {
+	return "# " + f.Source + ": " + f.Detail
+}
@@ -0,0 +1,80 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+func TestImageService(t *testing.T) {
+	for image, want := range map[string]string{
+		"postgres:16-alpine":                   "postgres",
+		"docker.io/library/redis:7@sha256:ab":  "redis",
+		"registry.internal:5000/bitnami/kafka": "kafka",
+		"localstack/localstack":                "aws",
+		"ghcr.io/acme/api:1":                   "",
+	} {
+		got, ok := ImageService(image)
+		if got != want || ok != (want != "") {
+			t.Errorf("ImageService(%q) = %q, %v; want %q", image, got, ok, want)
+		}
+	}
+}
+
+func TestComposeCompare(t *testing.T) {
+	dir := t.TempDir()
+	compose := `services:
+  app:
+    build: .
+    env_file:
+      - .env
+      - path: config/local.env
+        required: false
+  db:
+    image: postgres:16
+  cache:
+    image: redis:7
+`
+	if err := os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte(compose), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Detect(dir, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := aimap.Parse([]byte("version: 1\nsystem:\n  name: app\ndependencies:\n  external: [redis.cache]\nruntime:\n  config_paths: [config]\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, mm := range Compare(m, r) {
+		got = append(got, mm.Field+": "+mm.Message)
+	}
+	want := []string{
+		"dependencies.external: compose.yaml uses postgres (image postgres:16) but dependencies.external does not list it",
+		"runtime.config_paths: compose.yaml loads .env but runtime.config_paths does not cover it",
+		"runtime.environment: not set, but compose.yaml suggests container (service app is built here)",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Fatalf("mismatches:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	frag, err := r.Fragment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(frag), "environment: container # compose.yaml: service app is built here") {
+		t.Fatalf("fragment lacks the environment suggestion:\n%s", frag)
+	}
+	doc, err := aimap.Parse(frag)
+	if err != nil {
+		t.Fatalf("fragment does not parse: %v", err)
+	}
+	if len(doc.Dependencies.External) != 2 || len(doc.Runtime.ConfigPaths) != 2 {
+		t.Fatalf("fragment: %+v", doc)
+	}
+}
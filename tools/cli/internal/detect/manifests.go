@@ -0,0 +1,231 @@
+package detect
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ManifestFiles are the module manifests read for internal dependencies.
+var ManifestFiles = []string{"go.mod", "package.json", "pyproject.toml", "requirements.txt"}
+
+// Module is a dependency a manifest declares.
+type Module struct {
+	// Path is the module path or package name as written (normalized for Python).
+	Path string
+	// Source is the manifest file name.
+	Source string
+}
+
+// Manifests reads the module manifests in dir and returns the modules they
+// require, excluding the system's own module, and the manifest files read.
+func Manifests(dir string) ([]Module, []string, error) {
+	var out []Module
+	var files []string
+	for _, name := range ManifestFiles {
+		b, err := os.ReadFile(filepath.Join(dir, name))
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		files = append(files, name)
+		var self string
+		var deps []string
+		switch name {
+		case "go.mod":
+			self, deps = parseGoMod(b)
+		case "package.json":
+			if self, deps, err = parsePackageJSON(b); err != nil {
+				return nil, nil, fmt.Errorf("%s: %w", name, err)
+			}
+		case "pyproject.toml":
+			self, deps = parsePyProject(b)
+		case "requirements.txt":
+			deps = parseRequirements(b)
+		}
+		for _, d := range deps {
+			if d != "" && d != self {
+				out = append(out, Module{Path: d, Source: name})
+			}
+		}
+	}
+	return out, files, nil
+}
+
+// parseGoMod returns the module path and its direct requirements.
+func parseGoMod(b []byte) (self string, deps []string) {
+	inBlock := false
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		indirect := strings.HasSuffix(line, "// indirect")
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 0:
+		case inBlock && fields[0] == ")":
+			inBlock = false
+		case inBlock:
+			if !indirect {
+				deps = append(deps, fields[0])
+			}
+		case fields[0] == "module" && len(fields) > 1:
+			self = strings.Trim(fields[1], `"`)
+		case fields[0] == "require" && len(fields) > 1 && fields[1] == "(":
+			inBlock = true
+		case fields[0] == "require" && len(fields) > 1:
+			if !indirect {
+				deps = append(deps, fields[1])
+			}
+		}
+	}
+	return self, deps
+}
+
+// parsePackageJSON returns the package name and its runtime dependencies
+// (dependencies, peerDependencies, optionalDependencies; not devDependencies).
+func parsePackageJSON(b []byte) (string, []string, error) {
+	var pkg struct {
+		Name                 string            `json:"name"`
+		Dependencies         map[string]string `json:"dependencies"`
+		PeerDependencies     map[string]string `json:"peerDependencies"`
+		OptionalDependencies map[string]string `json:"optionalDependencies"`
+	}
+	if err := json.Unmarshal(b, &pkg); err != nil {
+		return "", nil, fmt.Errorf("JSON parse error: %w", err)
+	}
+	var deps []string
+	for _, m := range []map[string]string{pkg.Dependencies, pkg.PeerDependencies, pkg.OptionalDependencies} {
+		for d := range m {
+			deps = append(deps, d)
+		}
+	}
+	sort.Strings(deps)
+	return pkg.Name, deps, nil
+}
+
+// requirementName matches the distribution name at the start of a PEP 508 requirement.
+var requirementName = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*`)
+
+// parsePyProject reads [project] name/dependencies and [tool.poetry] name and
+// dependencies with a line scanner; it understands the common layouts only.
+func parsePyProject(b []byte) (self string, deps []string) {
+	var table string
+	inArray := false
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.Index(line, "#"); i >= 0 && !strings.ContainsAny(line[:i], `"'`) {
+			line = strings.TrimSpace(line[:i])
+		}
+		if line == "" {
+			continue
+		}
+		if inArray {
+			deps = append(deps, quotedRequirements(line)...)
+			if strings.Contains(line, "]") {
+				inArray = false
+			}
+			continue
+		}
+		if strings.HasPrefix(line, "[") {
+			table = strings.Trim(line, "[] ")
+			continue
+		}
+		key, val, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		key, val = strings.TrimSpace(key), strings.TrimSpace(val)
+		switch {
+		case (table == "project" || table == "tool.poetry") && key == "name":
+			self = pythonName(strings.Trim(val, `"'`))
+		case table == "project" && key == "dependencies":
+			deps = append(deps, quotedRequirements(val)...)
+			inArray = strings.HasPrefix(val, "[") && !strings.Contains(val, "]")
+		case table == "tool.poetry.dependencies" && key != "python":
+			deps = append(deps, pythonName(strings.Trim(key, `"'`)))
+		}
+	}
+	return self, deps
+}
+
+var quoted = regexp.MustCompile(`"([^"]*)"|'([^']*)'`)
+
+func quotedRequirements(s string) []string {
+	var out []string
+	for _, m := range quoted.FindAllStringSubmatch(s, -1) {
+		req := m[1] + m[2]
+		if n := requirementName.FindString(strings.TrimSpace(req)); n != "" {
+			out = append(out, pythonName(n))
+		}
+	}
+	return out
+}
+
+// parseRequirements reads requirement names and VCS URLs from requirements.txt.
+// A URL requirement yields its host and path, e.g. github.com/org/repo.
+func parseRequirements(b []byte) []string {
+	var deps []string
+	sc := bufio.NewScanner(bytes.NewReader(b))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if i := strings.Index(line, " #"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "-e "))
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "-") {
+			continue
+		}
+		if _, url, ok := strings.Cut(line, "://"); ok {
+			if i := strings.IndexAny(url, "@#"); i >= 0 {
+				url = url[:i]
+			}
+			deps = append(deps, strings.TrimSuffix(strings.TrimSuffix(url, "/"), ".git"))
+			continue
+		}
+		if n := requirementName.FindString(line); n != "" {
+			deps = append(deps, pythonName(n))
+		}
+	}
+	return deps
+}
+
+// pythonName normalizes a distribution name (PEP 503): lower case, runs of
+// -, _ and . become a single -.
+func pythonName(s string) string {
+	return strings.ToLower(pythonSeparators.ReplaceAllString(s, "-"))
+}
+
+var pythonSeparators = regexp.MustCompile(`[-_.]+`)
+
+// Prefix is an organisation prefix such as "github.com/ourorg/*" or "@ourorg/*".
+type Prefix string
+
+// Match returns the name of the repository or package a module path refers
+// to under the prefix: the segment the trailing "*" stands for.
+func (p Prefix) Match(module string) (string, bool) {
+	pat := string(p)
+	base := strings.TrimSuffix(pat, "*")
+	if base == pat && !strings.HasSuffix(base, "/") {
+		base += "/"
+	}
+	rest, ok := strings.CutPrefix(strings.ToLower(module), strings.ToLower(base))
+	if !ok || rest == "" {
+		return "", false
+	}
+	if i := strings.Index(rest, "/"); i >= 0 {
+		rest = rest[:i]
+	}
+	return rest, true
+}
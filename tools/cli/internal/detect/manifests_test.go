@@ -0,0 +1,77 @@
+package detect
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+func TestManifestParsers(t *testing.T) {
+	self, deps := parseGoMod([]byte("module github.com/ourorg/orders\n\nrequire github.com/ourorg/users v1.2.0\n\nrequire (\n\tgithub.com/ourorg/billing v0.3.0\n\tgithub.com/ourorg/tools v0.1.0 // indirect\n)\n"))
+	if self != "github.com/ourorg/orders" || !reflect.DeepEqual(deps, []string{"github.com/ourorg/users", "github.com/ourorg/billing"}) {
+		t.Errorf("go.mod: got %q %v", self, deps)
+	}
+
+	_, deps, err := parsePackageJSON([]byte(`{"name":"@ourorg/web","dependencies":{"@ourorg/users-client":"^1"},"devDependencies":{"@ourorg/lint":"^1"}}`))
+	if err != nil || !reflect.DeepEqual(deps, []string{"@ourorg/users-client"}) {
+		t.Errorf("package.json: got %v %v", deps, err)
+	}
+
+	self, deps = parsePyProject([]byte("[project]\nname = \"ourorg_orders\"\ndependencies = [\n  \"ourorg-users>=1.0\",\n  \"requests\",\n]\n"))
+	if self != "ourorg-orders" || !reflect.DeepEqual(deps, []string{"ourorg-users", "requests"}) {
+		t.Errorf("pyproject.toml: got %q %v", self, deps)
+	}
+
+	deps = parseRequirements([]byte("# pinned\nOurorg.Billing==2.0\n-r base.txt\ngit+https://github.com/ourorg/users.git@v1#egg=users\n"))
+	if !reflect.DeepEqual(deps, []string{"ourorg-billing", "github.com/ourorg/users"}) {
+		t.Errorf("requirements.txt: got %v", deps)
+	}
+}
+
+func TestPrefixMatch(t *testing.T) {
+	for _, tc := range []struct {
+		prefix, module, want string
+		ok                   bool
+	}{
+		{"github.com/ourorg/*", "github.com/ourorg/users/v2", "users", true},
+		{"github.com/ourorg", "github.com/OurOrg/users", "users", true},
+		{"@ourorg/*", "@ourorg/users-client", "users-client", true},
+		{"ourorg-*", "ourorg-billing", "billing", true},
+		{"github.com/ourorg/*", "github.com/other/users", "", false},
+	} {
+		got, ok := Prefix(tc.prefix).Match(tc.module)
+		if got != tc.want || ok != tc.ok {
+			t.Errorf("Prefix(%q).Match(%q) = %q, %v; want %q, %v", tc.prefix, tc.module, got, ok, tc.want, tc.ok)
+		}
+	}
+}
+
+func TestDetectInternal(t *testing.T) {
+	dir := t.TempDir()
+	gomod := "module github.com/ourorg/orders\n\nrequire (\n\tgithub.com/ourorg/user-service v1.0.0\n\tgithub.com/ourorg/ledger v1.0.0\n)\n"
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(gomod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	r, err := Detect(dir, Options{InternalPrefixes: []string{"github.com/ourorg/*"}, Systems: []string{"user_service", "orders"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, f := range r.Values(FieldInternal) {
+		got = append(got, f.Value)
+	}
+	if !reflect.DeepEqual(got, []string{"user_service", "ledger"}) {
+		t.Fatalf("internal findings: got %v", got)
+	}
+
+	m := &aimap.Map{}
+	m.Dependencies.Internal = []string{"user-service", "inventory"}
+	mm := Compare(m, r)
+	if len(mm) != 2 || !strings.Contains(mm[0].Message, "does not list ledger") || !strings.Contains(mm[1].Message, "lists inventory but no manifest (go.mod)") {
+		t.Fatalf("mismatches: got %v", mm)
+	}
+}
@@ -0,0 +1,335 @@
+// Package diff computes a semantic, field-level comparison of two AI-Map documents.
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/cjson"
+)
+
+type Kind string
+
+const (
+	KindAdded   Kind = "added"
+	KindRemoved Kind = "removed"
+	KindChanged Kind = "changed"
+)
+
+// Change describes one field-level difference. For lists of scalars (paths,
+// dependency names) each element is reported separately with Path naming the list.
+type Change struct {
+	Kind Kind
+	Path string
+	Old  any
+	New  any
+	// Risk is a short reason when the change is relevant for review; empty otherwise.
+	Risk string
+}
+
+type Report struct {
+	Changes []Change
+}
+
+// HasRisk reports whether any change was flagged as risk-relevant.
+func (r Report) HasRisk() bool {
+	for _, c := range r.Changes {
+		if c.Risk != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// Count returns the number of changes of kind k.
+func (r Report) Count(k Kind) int {
+	n := 0
+	for _, c := range r.Changes {
+		if c.Kind == k {
+			n++
+		}
+	}
+	return n
+}
+
+// CompareYAML decodes both documents and compares them.
+func CompareYAML(oldBytes, newBytes []byte) (Report, error) {
+	oldDoc, err := aimap.Decode(oldBytes)
+	if err != nil {
+		return Report{}, fmt.Errorf("old: %w", err)
+	}
+	newDoc, err := aimap.Decode(newBytes)
+	if err != nil {
+		return Report{}, fmt.Errorf("new: %w", err)
+	}
+	return Compare(oldDoc, newDoc), nil
+}
+
+// Compare walks two JSON-ready documents and returns changes in deterministic order.
+func Compare(oldDoc, newDoc map[string]any) Report {
+	var out []Change
+	compareValue("", oldDoc, newDoc, &out)
+	for i := range out {
+		out[i].Risk = riskOf(out[i])
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Path != out[j].Path {
+			return out[i].Path < out[j].Path
+		}
+		return kindOrder(out[i].Kind) < kindOrder(out[j].Kind)
+	})
+	return Report{Changes: out}
+}
+
+func compareValue(path string, oldV, newV any, out *[]Change) {
+	om, oIsMap := oldV.(map[string]any)
+	nm, nIsMap := newV.(map[string]any)
+	// A missing section compares like an empty one so additions are reported per field.
+	if (oIsMap || oldV == nil) && (nIsMap || newV == nil) && (oIsMap || nIsMap) {
+		compareMaps(path, om, nm, out)
+		return
+	}
+
+	ol, oIsList := scalarList(oldV)
+	nl, nIsList := scalarList(newV)
+	if (oIsList || oldV == nil) && (nIsList || newV == nil) && (oIsList || nIsList) {
+		compareSets(path, ol, nl, out)
+		return
+	}
+
+	switch {
+	case oldV == nil && newV == nil:
+	case oldV == nil:
+		*out = append(*out, Change{Kind: KindAdded, Path: path, New: newV})
+	case newV == nil:
+		*out = append(*out, Change{Kind: KindRemoved, Path: path, Old: oldV})
+	case !reflect.DeepEqual(oldV, newV):
+		*out = append(*out, Change{Kind: KindChanged, Path: path, Old: oldV, New: newV})
+	}
+}
+
+func compareMaps(path string, oldM, newM map[string]any, out *[]Change) {
+	keys := make(map[string]struct{}, len(oldM)+len(newM))
+	for k := range oldM {
+		keys[k] = struct{}{}
+	}
+	for k := range newM {
+		keys[k] = struct{}{}
+	}
+	sorted := make([]string, 0, len(keys))
+	for k := range keys {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+	for _, k := range sorted {
+		compareValue(join(path, k), oldM[k], newM[k], out)
+	}
+}
+
+func compareSets(path string, oldL, newL []any, out *[]Change) {
+	inOld := make(map[string]bool, len(oldL))
+	for _, v := range oldL {
+		inOld[scalarKey(v)] = true
+	}
+	inNew := make(map[string]bool, len(newL))
+	for _, v := range newL {
+		inNew[scalarKey(v)] = true
+	}
+	for _, v := range oldL {
+		if !inNew[scalarKey(v)] {
+			*out = append(*out, Change{Kind: KindRemoved, Path: path, Old: v})
+		}
+	}
+	for _, v := range newL {
+		if !inOld[scalarKey(v)] {
+			*out = append(*out, Change{Kind: KindAdded, Path: path, New: v})
+		}
+	}
+}
+
+// scalarList reports whether v is a list made only of scalars (treated as a set).
+func scalarList(v any) ([]any, bool) {
+	l, ok := v.([]any)
+	if !ok {
+		return nil, false
+	}
+	for _, it := range l {
+		switch it.(type) {
+		case map[string]any, []any:
+			return nil, false
+		}
+	}
+	return l, true
+}
+
+func scalarKey(v any) string {
+	b, _ := json.Marshal(v)
+	return string(b)
+}
+
+func join(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func kindOrder(k Kind) int {
+	switch k {
+	case KindRemoved:
+		return 0
+	case KindAdded:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// riskOf flags changes reviewers should not miss: weakened protection,
+// new blast radius, or rerouted ownership.
+func riskOf(c Change) string {
+	switch {
+	case c.Path == "version" && c.Kind != KindAdded:
+		return "spec version changed"
+	case c.Path == "system.name" && c.Kind != KindAdded:
+		return "system identity changed"
+	case c.Path == "boundaries.critical" && c.Kind == KindRemoved:
+		return "critical path removed"
+	case strings.HasPrefix(c.Path, "boundaries.entrypoints.") && c.Kind == KindRemoved:
+		return "entrypoint removed"
+	case c.Path == "dependencies.external" && c.Kind == KindAdded:
+		return "new external dependency"
+	case c.Path == "dependencies.internal" && c.Kind == KindAdded:
+		return "new internal dependency"
+	case c.Path == "ownership.team" && c.Kind != KindAdded:
+		return "ownership changed"
+	case (c.Path == "runtime.environment" || c.Path == "runtime.deploys_via") && c.Kind != KindAdded:
+		return "runtime changed"
+	case c.Path == "extensions.ai-flow.ignore" && c.Kind == KindRemoved:
+		return "agent ignore rule removed"
+	case c.Path == "extensions.ai-flow.safe_write" && c.Kind == KindAdded:
+		return "agent write access widened"
+	default:
+		return ""
+	}
+}
+
+// Text renders one line per change: "+" added, "-" removed, "~" changed.
+func Text(r Report) []byte {
+	var b bytes.Buffer
+	if len(r.Changes) == 0 {
+		b.WriteString("no changes\n")
+		return b.Bytes()
+	}
+	for _, c := range r.Changes {
+		switch c.Kind {
+		case KindAdded:
+			fmt.Fprintf(&b, "+ %s: %s", c.Path, formatValue(c.New))
+		case KindRemoved:
+			fmt.Fprintf(&b, "- %s: %s", c.Path, formatValue(c.Old))
+		default:
+			fmt.Fprintf(&b, "~ %s: %s -> %s", c.Path, formatValue(c.Old), formatValue(c.New))
+		}
+		if c.Risk != "" {
+			fmt.Fprintf(&b, "  [risk: %s]", c.Risk)
+		}
+		b.WriteByte('\n')
+	}
+	fmt.Fprintf(&b, "\n%d added, %d removed, %d changed\n", r.Count(KindAdded), r.Count(KindRemoved), r.Count(KindChanged))
+	return b.Bytes()
+}
+
+// JSON renders the report as canonical JSON.
+func JSON(r Report) ([]byte, error) {
+	changes := make([]any, 0, len(r.Changes))
+	for _, c := range r.Changes {
+		m := map[string]any{"kind": string(c.Kind), "path": c.Path}
+		if c.Kind != KindAdded {
+			m["old"] = c.Old
+		}
+		if c.Kind != KindRemoved {
+			m["new"] = c.New
+		}
+		if c.Risk != "" {
+			m["risk"] = c.Risk
+		}
+		changes = append(changes, m)
+	}
+	return cjson.MarshalIndent(map[string]any{
+		"changes": changes,
+		"summary": map[string]any{
+			"added":   r.Count(KindAdded),
+			"removed": r.Count(KindRemoved),
+			"changed": r.Count(KindChanged),
+			"risky":   r.HasRisk(),
+		},
+	}, "", "  ")
+}
+
+// Markdown renders a summary suitable for a pull request comment.
+func Markdown(r Report, title string) []byte {
+	var b bytes.Buffer
+	if strings.TrimSpace(title) == "" {
+		title = "AI-Map changes"
+	}
+	fmt.Fprintf(&b, "### %s\n\n", title)
+	if len(r.Changes) == 0 {
+		b.WriteString("No semantic changes.\n")
+		return b.Bytes()
+	}
+	fmt.Fprintf(&b, "%d added, %d removed, %d changed.\n\n", r.Count(KindAdded), r.Count(KindRemoved), r.Count(KindChanged))
+
+	if r.HasRisk() {
+		b.WriteString("**Needs attention**\n\n")
+		for _, c := range r.Changes {
+			if c.Risk == "" {
+				continue
+			}
+			fmt.Fprintf(&b, "- **%s**: `%s` %s\n", c.Risk, c.Path, describe(c))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("| Change | Field | Old | New |\n")
+	b.WriteString("|---|---|---|---|\n")
+	for _, c := range r.Changes {
+		oldCell, newCell := "", ""
+		if c.Kind != KindAdded {
+			oldCell = "`" + mdEscape(formatValue(c.Old)) + "`"
+		}
+		if c.Kind != KindRemoved {
+			newCell = "`" + mdEscape(formatValue(c.New)) + "`"
+		}
+		fmt.Fprintf(&b, "| %s | `%s` | %s | %s |\n", c.Kind, c.Path, oldCell, newCell)
+	}
+	return b.Bytes()
+}
+
+func describe(c Change) string {
+	switch c.Kind {
+	case KindAdded:
+		return "added " + formatValue(c.New)
+	case KindRemoved:
+		return "removed " + formatValue(c.Old)
+	default:
+		return formatValue(c.Old) + " → " + formatValue(c.New)
+	}
+}
+
+func formatValue(v any) string {
+	b, err := cjson.MarshalIndent(v, "", "")
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	s := strings.TrimRight(string(b), "\n")
+	return strings.ReplaceAll(s, "\n", " ")
+}
+
+func mdEscape(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
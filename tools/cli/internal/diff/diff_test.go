@@ -0,0 +1,54 @@
+package diff
+
+import "testing"
+
+func TestCompareYAML_ClassifiesAndFlagsRisk(t *testing.T) {
+	oldYAML := []byte(`version: 1
+system: {name: a, type: service}
+boundaries:
+  critical: [src/core, src/pay]
+dependencies:
+  external: [redis]
+`)
+	newYAML := []byte(`version: 1
+system: {name: a, type: library, domain: billing}
+boundaries:
+  critical: [src/pay]
+dependencies:
+  external: [redis, stripe]
+`)
+
+	rep, err := CompareYAML(oldYAML, newYAML)
+	if err != nil {
+		t.Fatalf("CompareYAML: %v", err)
+	}
+
+	want := []Change{
+		{Kind: KindRemoved, Path: "boundaries.critical", Old: "src/core", Risk: "critical path removed"},
+		{Kind: KindAdded, Path: "dependencies.external", New: "stripe", Risk: "new external dependency"},
+		{Kind: KindAdded, Path: "system.domain", New: "billing"},
+		{Kind: KindChanged, Path: "system.type", Old: "service", New: "library"},
+	}
+	if len(rep.Changes) != len(want) {
+		t.Fatalf("got %d changes, want %d: %#v", len(rep.Changes), len(want), rep.Changes)
+	}
+	for i, w := range want {
+		if rep.Changes[i] != w {
+			t.Errorf("change %d: got %#v, want %#v", i, rep.Changes[i], w)
+		}
+	}
+	if !rep.HasRisk() {
+		t.Errorf("expected risk")
+	}
+}
+
+func TestCompareYAML_NoChanges(t *testing.T) {
+	b := []byte("version: 1\nsystem: {name: a}\n")
+	rep, err := CompareYAML(b, b)
+	if err != nil {
+		t.Fatalf("CompareYAML: %v", err)
+	}
+	if len(rep.Changes) != 0 || rep.HasRisk() {
+		t.Fatalf("expected no changes, got %#v", rep.Changes)
+	}
+}
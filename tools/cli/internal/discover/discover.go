@@ -0,0 +1,235 @@
+// Package discover walks a directory tree the way git sees it: .gitignore files
+// (at every level), .git/info/exclude and a fixed set of dependency directories
+// are skipped. It finds map files for every command and backs repo scans.
+package discover
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/ignore"
+)
+
+// DefaultName is the map file name looked for when Options.Names is empty.
+const DefaultName = ".ai-map.yaml"
+
+// SkipDirs are never descended into.
+var SkipDirs = map[string]bool{".git": true, "node_modules": true, "vendor": true}
+
+// Options narrows a walk.
+type Options struct {
+	// Names are the file names Find matches (DefaultName when empty).
+	Names []string
+	// Include limits results to files matching at least one gitignore-style glob
+	// (relative to the walk root). Empty means everything.
+	Include []string
+	// Exclude drops files and directories matching any gitignore-style glob.
+	Exclude []string
+}
+
+// Find returns the absolute paths of files under root named like opt.Names, sorted.
+func Find(root string, opt Options) ([]string, error) {
+	names := opt.Names
+	if len(names) == 0 {
+		names = []string{DefaultName}
+	}
+	want := make(map[string]bool, len(names))
+	for _, n := range names {
+		want[n] = true
+	}
+	var out []string
+	err := Walk(root, opt, func(abs, rel string, d fs.DirEntry) error {
+		if want[d.Name()] {
+			out = append(out, abs)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// Walk calls fn for every regular file under root that is not ignored and passes
+// the include/exclude filters. rel is slash-separated and relative to root.
+func Walk(root string, opt Options, fn func(abs, rel string, d fs.DirEntry) error) error {
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return err
+	}
+	st, err := os.Stat(absRoot)
+	if err != nil {
+		return fmt.Errorf("cannot stat %q: %w", root, err)
+	}
+	if !st.IsDir() {
+		return fmt.Errorf("%q is not a directory", root)
+	}
+
+	include, err := compile(opt.Include)
+	if err != nil {
+		return fmt.Errorf("--include: %w", err)
+	}
+	exclude, err := compile(opt.Exclude)
+	if err != nil {
+		return fmt.Errorf("--exclude: %w", err)
+	}
+
+	w := newWalker(absRoot)
+	return w.walk(absRoot, func(abs string, d fs.DirEntry) (bool, error) {
+		rel, _ := filepath.Rel(absRoot, abs)
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			return !anyMatch(exclude, rel, true), nil
+		}
+		if anyMatch(exclude, rel, false) {
+			return false, nil
+		}
+		if len(include) > 0 && !anyMatch(include, rel, false) {
+			return false, nil
+		}
+		return true, fn(abs, rel, d)
+	})
+}
+
+func compile(globs []string) ([]ignore.Rule, error) {
+	var out []ignore.Rule
+	for _, g := range globs {
+		r, ok := ignore.ParseRule(g)
+		if !ok {
+			return nil, fmt.Errorf("empty pattern %q", g)
+		}
+		if r.Negate {
+			return nil, fmt.Errorf("negated pattern %q is not supported", g)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+func anyMatch(rules []ignore.Rule, rel string, isDir bool) bool {
+	for _, r := range rules {
+		if r.MatchTree(rel, isDir) {
+			return true
+		}
+	}
+	return false
+}
+
+// walker tracks ignore rules relative to the enclosing git work tree (or the
+// walk root when there is none).
+type walker struct {
+	base     string
+	root     string
+	matchers []ignore.Matcher
+}
+
+func newWalker(root string) *walker {
+	w := &walker{base: root, root: root}
+	if repo, ok := findRepoRoot(root); ok {
+		w.base = repo
+		if b, err := os.ReadFile(filepath.Join(repo, ".git", "info", "exclude")); err == nil {
+			w.matchers = append(w.matchers, ignore.Parse(b, ""))
+		}
+	}
+	// .gitignore files from the base down to the walk root all apply.
+	dir := w.base
+	w.load(dir)
+	if rel, _ := filepath.Rel(w.base, root); rel != "." {
+		for _, seg := range strings.Split(filepath.ToSlash(rel), "/") {
+			dir = filepath.Join(dir, seg)
+			w.load(dir)
+		}
+	}
+	return w
+}
+
+// load pushes dir/.gitignore if present and reports whether it did.
+func (w *walker) load(dir string) bool {
+	b, err := os.ReadFile(filepath.Join(dir, ".gitignore"))
+	if err != nil {
+		return false
+	}
+	rel, _ := filepath.Rel(w.base, dir)
+	rel = filepath.ToSlash(rel)
+	if rel == "." {
+		rel = ""
+	}
+	w.matchers = append(w.matchers, ignore.Parse(b, rel))
+	return true
+}
+
+// ignored applies matchers from most to least specific; the first decision wins.
+func (w *walker) ignored(abs string, isDir bool) bool {
+	rel, err := filepath.Rel(w.base, abs)
+	if err != nil {
+		return false
+	}
+	rel = filepath.ToSlash(rel)
+	for i := len(w.matchers) - 1; i >= 0; i-- {
+		if ign, ok := w.matchers[i].Decide(rel, isDir); ok {
+			return ign
+		}
+	}
+	return false
+}
+
+// walk visits dir recursively in lexical order. visit returns false to skip a
+// file or prune a directory.
+func (w *walker) walk(dir string, visit func(abs string, d fs.DirEntry) (bool, error)) error {
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("cannot read dir %q: %w", dir, err)
+	}
+	if dir != w.root && w.load(dir) {
+		defer func() { w.matchers = w.matchers[:len(w.matchers)-1] }()
+	}
+
+	for _, e := range ents {
+		abs := filepath.Join(dir, e.Name())
+		isDir := e.IsDir()
+		if e.Type()&fs.ModeSymlink != 0 {
+			// Symlinked directories are not followed.
+			if st, err := os.Stat(abs); err != nil || st.IsDir() {
+				continue
+			}
+		}
+		if isDir && SkipDirs[e.Name()] {
+			continue
+		}
+		if w.ignored(abs, isDir) {
+			continue
+		}
+		ok, err := visit(abs, e)
+		if err != nil {
+			return err
+		}
+		if ok && isDir {
+			if err := w.walk(abs, visit); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// findRepoRoot returns the nearest ancestor of dir (inclusive) containing .git.
+func findRepoRoot(dir string) (string, bool) {
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return dir, true
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return "", false
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
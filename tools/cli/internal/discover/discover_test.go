@@ -0,0 +1,83 @@
+package discover
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		".git/HEAD":                         "ref: refs/heads/main\n",
+		".git/info/exclude":                 "scratch/\n",
+		".gitignore":                        "dist/\n*.tmp.yaml\n",
+		".ai-map.yaml":                      "",
+		"svc/a/.ai-map.yaml":                "",
+		"svc/b/.gitignore":                  "generated/\n!keep.tmp.yaml\n",
+		"svc/b/generated/.ai-map.yaml":      "",
+		"svc/b/keep.tmp.yaml":               "",
+		"dist/.ai-map.yaml":                 "",
+		"scratch/.ai-map.yaml":              "",
+		"node_modules/x/.ai-map.yaml":       "",
+		"examples/demo/.ai-map.yaml":        "",
+		"examples/demo/nested/.ai-map.yaml": "",
+	}
+	for p, c := range files {
+		abs := filepath.Join(root, filepath.FromSlash(p))
+		if err := os.MkdirAll(filepath.Dir(abs), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(abs, []byte(c), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rel := func(paths []string) []string {
+		out := make([]string, 0, len(paths))
+		for _, p := range paths {
+			r, _ := filepath.Rel(root, p)
+			out = append(out, filepath.ToSlash(r))
+		}
+		return out
+	}
+
+	got, err := Find(root, Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{".ai-map.yaml", "examples/demo/.ai-map.yaml", "examples/demo/nested/.ai-map.yaml", "svc/a/.ai-map.yaml"}
+	if !reflect.DeepEqual(rel(got), want) {
+		t.Fatalf("Find = %v, want %v", rel(got), want)
+	}
+
+	got, err = Find(root, Options{Names: []string{"keep.tmp.yaml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"svc/b/keep.tmp.yaml"}; !reflect.DeepEqual(rel(got), want) {
+		t.Fatalf("negated .gitignore: got %v, want %v", rel(got), want)
+	}
+
+	got, err = Find(root, Options{Include: []string{"svc/**", "examples/**"}, Exclude: []string{"nested"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"examples/demo/.ai-map.yaml", "svc/a/.ai-map.yaml"}; !reflect.DeepEqual(rel(got), want) {
+		t.Fatalf("include/exclude: got %v, want %v", rel(got), want)
+	}
+
+	// Walking a subdirectory still honours ignore files above it.
+	got, err = Find(filepath.Join(root, "svc"), Options{Names: []string{".ai-map.yaml", "keep.tmp.yaml"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"svc/a/.ai-map.yaml", "svc/b/keep.tmp.yaml"}; !reflect.DeepEqual(rel(got), want) {
+		t.Fatalf("subdir walk: got %v, want %v", rel(got), want)
+	}
+
+	if _, err := Find(root, Options{Exclude: []string{"!x"}}); err == nil {
+		t.Fatal("expected error for negated exclude")
+	}
+}
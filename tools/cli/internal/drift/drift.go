@@ -0,0 +1,317 @@
+// Package drift compares a map with the repository it describes and reports
+// where they contradict each other, most serious first, with suggested edits.
+package drift
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/sources"
+)
+
+// Severities, most serious first.
+const (
+	SeverityHigh   = "high"
+	SeverityMedium = "medium"
+	SeverityLow    = "low"
+)
+
+var severityRank = map[string]int{SeverityHigh: 0, SeverityMedium: 1, SeverityLow: 2}
+
+// Kinds of drift.
+const (
+	KindMissingPath     = "missing-path"
+	KindLanguage        = "language"
+	KindEmptyEntrypoint = "empty-entrypoint"
+	KindUncoveredDir    = "uncovered-dir"
+	KindUnlistedConfig  = "unlisted-config"
+)
+
+// Finding is one contradiction between the map and the repository.
+type Finding struct {
+	Severity string
+	Kind     string
+	// Field is the map field the suggested edit touches.
+	Field string
+	// Path is the repository path concerned, relative to the map directory; empty
+	// when the finding is about the system as a whole.
+	Path       string
+	Message    string
+	Suggestion string
+}
+
+// skipDirs are top-level directories that hold tests, docs or examples rather
+// than the system's code, and need no boundary.
+var skipDirs = map[string]bool{
+	"test": true, "tests": true, "testdata": true, "__tests__": true, "__mocks__": true,
+	"spec": true, "e2e": true, "fixtures": true, "docs": true, "doc": true, "examples": true,
+	"example": true, "scripts": true,
+}
+
+// configDirs are top-level directories whose files are configuration.
+var configDirs = map[string]bool{"config": true, "configs": true}
+
+// Check reports how the map m at mapDir has drifted from the files around it.
+func Check(mapDir string, m *aimap.Map) ([]Finding, error) {
+	inv, err := sources.Walk(mapDir, m)
+	if err != nil {
+		return nil, err
+	}
+	var out []Finding
+	out = append(out, missingPaths(mapDir, m)...)
+	out = append(out, language(m, inv)...)
+	out = append(out, emptyEntrypoints(mapDir, m, inv)...)
+	out = append(out, uncoveredDirs(m, inv)...)
+	out = append(out, unlistedConfig(m, inv)...)
+	sort.SliceStable(out, func(i, j int) bool {
+		return severityRank[out[i].Severity] < severityRank[out[j].Severity]
+	})
+	return out, nil
+}
+
+// local reports whether a map path refers to a file in the repository.
+func local(p string) bool {
+	p = strings.TrimSpace(p)
+	return p != "" && !strings.Contains(p, "://") && !strings.HasPrefix(p, "/")
+}
+
+func exists(mapDir, p string) bool {
+	_, err := os.Stat(filepath.Join(mapDir, filepath.FromSlash(aimap.StaticPrefix(p))))
+	return err == nil
+}
+
+// missingPaths reports boundary paths that no longer exist.
+func missingPaths(mapDir string, m *aimap.Map) []Finding {
+	var out []Finding
+	check := func(field string, paths []string) {
+		for _, p := range paths {
+			if !local(p) || exists(mapDir, p) {
+				continue
+			}
+			out = append(out, Finding{
+				Severity:   SeverityHigh,
+				Kind:       KindMissingPath,
+				Field:      field,
+				Path:       aimap.CleanPath(p),
+				Message:    fmt.Sprintf("%s lists %s, which does not exist", field, p),
+				Suggestion: fmt.Sprintf("remove %s from %s or point it at the new location", p, field),
+			})
+		}
+	}
+	for _, proto := range m.Boundaries.Protocols() {
+		check("boundaries.entrypoints."+proto, m.Boundaries.Entrypoints[proto])
+	}
+	check("boundaries.models", m.Boundaries.Models)
+	check("boundaries.critical", m.Boundaries.Critical)
+	return out
+}
+
+// language compares system.language with the languages of the source files.
+func language(m *aimap.Map, inv sources.Inventory) []Finding {
+	counts := map[string]int{}
+	for _, s := range inv.Sources {
+		counts[sources.Language(s)]++
+	}
+	dominant := ""
+	for lang, n := range counts {
+		if n > counts[dominant] || (n == counts[dominant] && lang < dominant) {
+			dominant = lang
+		}
+	}
+	if dominant == "" {
+		return nil
+	}
+	total := len(inv.Sources)
+	share := fmt.Sprintf("%s has %d of %d source files", dominant, counts[dominant], total)
+	suggestion := "system.language: " + dominant
+
+	declared := sources.NormalizeLanguage(m.System.Language)
+	switch {
+	case declared == "":
+		return []Finding{{SeverityLow, KindLanguage, "system.language", "", "system.language is not set; " + share, suggestion}}
+	case declared == dominant || !knownLanguage(declared):
+		return nil
+	case counts[declared] == 0:
+		return []Finding{{SeverityHigh, KindLanguage, "system.language", "", fmt.Sprintf("system.language is %s, but there are no %s source files; %s", m.System.Language, declared, share), suggestion}}
+	default:
+		return []Finding{{SeverityMedium, KindLanguage, "system.language", "", fmt.Sprintf("system.language is %s (%d source files), but %s", m.System.Language, counts[declared], share), suggestion}}
+	}
+}
+
+func knownLanguage(lang string) bool {
+	for _, l := range sources.Languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
+
+// emptyEntrypoints reports entrypoint directories and globs that match no
+// source file. Entrypoints naming a single existing file are left alone.
+func emptyEntrypoints(mapDir string, m *aimap.Map, inv sources.Inventory) []Finding {
+	var out []Finding
+	for _, proto := range m.Boundaries.Protocols() {
+		field := "boundaries.entrypoints." + proto
+		for _, e := range m.Boundaries.Entrypoints[proto] {
+			if !local(e) || !exists(mapDir, e) {
+				continue
+			}
+			if e == aimap.StaticPrefix(e) {
+				if st, err := os.Stat(filepath.Join(mapDir, filepath.FromSlash(e))); err != nil || !st.IsDir() {
+					continue
+				}
+			}
+			if matchCount(e, inv.Sources) > 0 {
+				continue
+			}
+			out = append(out, Finding{
+				Severity:   SeverityMedium,
+				Kind:       KindEmptyEntrypoint,
+				Field:      field,
+				Path:       aimap.CleanPath(e),
+				Message:    fmt.Sprintf("%s lists %s, which contains no source files", field, e),
+				Suggestion: fmt.Sprintf("point %s at the handlers' directory or remove it", e),
+			})
+		}
+	}
+	return out
+}
+
+func matchCount(pattern string, files []string) int {
+	n := 0
+	for _, f := range files {
+		if aimap.MatchPath(pattern, f) {
+			n++
+		}
+	}
+	return n
+}
+
+// uncoveredDirs reports top-level directories with source files that no
+// entrypoint, model or critical path reaches into.
+func uncoveredDirs(m *aimap.Map, inv sources.Inventory) []Finding {
+	var patterns []string
+	patterns = append(patterns, m.Boundaries.AllEntrypoints()...)
+	patterns = append(patterns, m.Boundaries.Models...)
+	patterns = append(patterns, m.Boundaries.Critical...)
+
+	counts := map[string]int{}
+	var dirs []string
+	for _, s := range inv.Sources {
+		dir, _, ok := strings.Cut(s, "/")
+		if !ok || strings.HasPrefix(dir, ".") || skipDirs[dir] || configDirs[dir] || sources.IsTest(s) {
+			continue
+		}
+		if counts[dir] == 0 {
+			dirs = append(dirs, dir)
+		}
+		counts[dir]++
+	}
+
+	var out []Finding
+	for _, dir := range dirs {
+		if covered(dir, patterns, inv.Sources) {
+			continue
+		}
+		out = append(out, Finding{
+			Severity:   SeverityMedium,
+			Kind:       KindUncoveredDir,
+			Field:      "boundaries",
+			Path:       dir,
+			Message:    fmt.Sprintf("%s/ holds %d source file(s), but no entrypoint, model or critical path covers it", dir, counts[dir]),
+			Suggestion: fmt.Sprintf("add %s to boundaries.entrypoints, boundaries.models or boundaries.critical, or to extensions.ai-flow.ignore", dir),
+		})
+	}
+	return out
+}
+
+// covered reports whether a pattern covers dir or reaches one of its files.
+func covered(dir string, patterns, files []string) bool {
+	for _, p := range patterns {
+		if !local(p) {
+			continue
+		}
+		if aimap.MatchPath(p, dir) || strings.HasPrefix(aimap.CleanPath(p), dir+"/") {
+			return true
+		}
+	}
+	for _, f := range files {
+		if strings.HasPrefix(f, dir+"/") {
+			for _, p := range patterns {
+				if local(p) && aimap.MatchPath(p, f) {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// configName matches file names that hold a system's configuration.
+func configName(base string) bool {
+	base = strings.ToLower(base)
+	ext := path.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	switch {
+	case base == ".env" || strings.HasPrefix(base, ".env."):
+		return true
+	case ext == ".json" && (stem == "appsettings" || strings.HasPrefix(stem, "appsettings.")):
+		return true
+	case ext == ".yml" || ext == ".yaml" || ext == ".properties":
+		if stem == "application" || strings.HasPrefix(stem, "application-") || stem == "bootstrap" {
+			return true
+		}
+	}
+	switch ext {
+	case ".yml", ".yaml", ".json", ".toml":
+		return stem == "config" || stem == "settings"
+	}
+	return false
+}
+
+// unlistedConfig reports configuration files runtime.config_paths does not cover.
+// A top-level config/ directory is reported once.
+func unlistedConfig(m *aimap.Map, inv sources.Inventory) []Finding {
+	var out []Finding
+	seen := map[string]bool{}
+	for _, f := range inv.Files {
+		p := f
+		if dir, _, ok := strings.Cut(f, "/"); ok && configDirs[dir] {
+			p = dir
+		} else if !configName(path.Base(f)) {
+			continue
+		}
+		if seen[p] || coveredBy(m.Runtime.ConfigPaths, f) {
+			continue
+		}
+		seen[p] = true
+		shown := p
+		if p != f {
+			shown += "/"
+		}
+		out = append(out, Finding{
+			Severity:   SeverityLow,
+			Kind:       KindUnlistedConfig,
+			Field:      "runtime.config_paths",
+			Path:       p,
+			Message:    fmt.Sprintf("%s looks like configuration, but runtime.config_paths does not cover it", shown),
+			Suggestion: fmt.Sprintf("add %s to runtime.config_paths", p),
+		})
+	}
+	return out
+}
+
+func coveredBy(patterns []string, f string) bool {
+	for _, p := range patterns {
+		if local(p) && aimap.MatchPath(p, f) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,62 @@
+package drift
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+func TestCheck(t *testing.T) {
+	dir := t.TempDir()
+	for _, f := range []string{"src/api/users.ts", "src/api/orders.ts", "lib/util.ts", "tests/users.test.ts", "web/empty/README.md", "config/app.yaml", "other/.ai-map.yaml", "other/main.go", "gen/big.ts"} {
+		p := filepath.Join(dir, filepath.FromSlash(f))
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("x\n"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	m, err := aimap.Parse([]byte(`version: 1
+system:
+  name: users
+  language: go
+boundaries:
+  entrypoints:
+    http: [src/api, web/empty]
+    queue: [src/queue]
+  models: [src/api/users.ts]
+runtime:
+  config_paths: [.env]
+extensions:
+  ai-flow:
+    ignore: [gen]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := Check(dir, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct{ severity, kind, path string }{
+		{SeverityHigh, KindMissingPath, "src/queue"},
+		{SeverityHigh, KindLanguage, ""},
+		{SeverityMedium, KindEmptyEntrypoint, "web/empty"},
+		{SeverityMedium, KindUncoveredDir, "lib"},
+		{SeverityLow, KindUnlistedConfig, "config"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("got %d findings, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Severity != w.severity || got[i].Kind != w.kind || got[i].Path != w.path {
+			t.Errorf("finding %d: got %s %s %q, want %s %s %q", i+1, got[i].Severity, got[i].Kind, got[i].Path, w.severity, w.kind, w.path)
+		}
+	}
+	if got[1].Suggestion != "system.language: typescript" {
+		t.Errorf("language suggestion: got %q", got[1].Suggestion)
+	}
+}
@@ -0,0 +1,192 @@
+// Package git reads repository state through the local git binary.
+// Only read-only, offline subcommands are used.
+package git
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Show returns the contents of path as of rev (like `git show REV:./path`).
+// path may be absolute or relative to the working directory; it is resolved
+// against the repository that contains it. At most maxBytes are read.
+func Show(rev, path string, maxBytes int64) ([]byte, error) {
+	if strings.TrimSpace(rev) == "" {
+		return nil, errors.New("git revision is required")
+	}
+	if strings.HasPrefix(rev, "-") {
+		return nil, fmt.Errorf("invalid git revision %q", rev)
+	}
+	if maxBytes <= 0 {
+		return nil, errors.New("maxBytes must be > 0")
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	dir, base := filepath.Split(abs)
+
+	cmd := exec.Command("git", "-C", dir, "show", rev+":./"+base)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("cannot run git: %w", err)
+	}
+	b, readErr := io.ReadAll(io.LimitReader(out, maxBytes+1))
+	if int64(len(b)) > maxBytes {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, fmt.Errorf("file too large (> %d bytes): %s:%s", maxBytes, rev, path)
+	}
+	if err := cmd.Wait(); err != nil {
+		return nil, fmt.Errorf("git show %s:%s: %s", rev, path, firstLine(stderr.String(), err))
+	}
+	if readErr != nil {
+		return nil, readErr
+	}
+	return b, nil
+}
+
+func firstLine(s string, fallback error) string {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return fallback.Error()
+	}
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// TopLevel returns the absolute root of the work tree containing dir.
+func TopLevel(dir string) (string, error) {
+	out, err := run(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return "", err
+	}
+	return filepath.FromSlash(strings.TrimSpace(string(out))), nil
+}
+
+// StagedFiles lists paths (relative to the repo root) with staged changes,
+// including deletions. A rename lists both its old and new path.
+func StagedFiles(dir string) ([]string, error) {
+	out, err := run(dir, "diff", "--cached", "--name-only", "--no-renames", "-z")
+	if err != nil {
+		return nil, err
+	}
+	return splitNUL(out), nil
+}
+
+// ChangedFiles lists paths (relative to the repo root) changed between the merge
+// base of base and HEAD, and HEAD (`git diff base...HEAD`), including deletions.
+// A rename lists both its old and new path.
+func ChangedFiles(dir, base string) ([]string, error) {
+	if strings.TrimSpace(base) == "" {
+		return nil, errors.New("base revision is required")
+	}
+	if strings.HasPrefix(base, "-") {
+		return nil, fmt.Errorf("invalid base revision %q", base)
+	}
+	out, err := run(dir, "diff", "--name-only", "--no-renames", "-z", base+"...HEAD")
+	if err != nil {
+		return nil, err
+	}
+	return splitNUL(out), nil
+}
+
+// MergeBase returns the best common ancestor of base and HEAD, the revision
+// ChangedFiles compares against.
+func MergeBase(dir, base string) (string, error) {
+	if strings.HasPrefix(base, "-") {
+		return "", fmt.Errorf("invalid base revision %q", base)
+	}
+	out, err := run(dir, "merge-base", base, "HEAD")
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// HasHEAD reports whether the repository containing dir has a commit checked out.
+func HasHEAD(dir string) bool {
+	_, err := run(dir, "rev-parse", "--verify", "--quiet", "HEAD")
+	return err == nil
+}
+
+// TrackedFiles lists the paths (relative to the repo root) of every file in
+// the tree of rev.
+func TrackedFiles(dir, rev string) ([]string, error) {
+	if strings.HasPrefix(rev, "-") {
+		return nil, fmt.Errorf("invalid git revision %q", rev)
+	}
+	out, err := run(dir, "ls-tree", "-r", "-z", "--name-only", "--full-tree", rev)
+	if err != nil {
+		return nil, err
+	}
+	return splitNUL(out), nil
+}
+
+// LastCommitTime returns the committer time of the last commit that touched
+// path. It fails when path is outside a repository or has never been committed.
+func LastCommitTime(path string) (time.Time, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	dir, base := filepath.Split(abs)
+	out, err := run(dir, "log", "-1", "--format=%ct", "--", base)
+	if err != nil {
+		return time.Time{}, err
+	}
+	s := strings.TrimSpace(string(out))
+	if s == "" {
+		return time.Time{}, fmt.Errorf("%s is not committed", path)
+	}
+	sec, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("git log: unexpected output %q", s)
+	}
+	return time.Unix(sec, 0), nil
+}
+
+// HooksDir returns the absolute hooks directory for the repository containing dir,
+// honouring core.hooksPath and worktrees.
+func HooksDir(dir string) (string, error) {
+	out, err := run(dir, "rev-parse", "--path-format=absolute", "--git-path", "hooks")
+	if err != nil {
+		return "", err
+	}
+	return filepath.FromSlash(strings.TrimSpace(string(out))), nil
+}
+
+func run(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %s", args[0], firstLine(stderr.String(), err))
+	}
+	return out, nil
+}
+
+func splitNUL(b []byte) []string {
+	var out []string
+	for _, p := range strings.Split(string(b), "\x00") {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
@@ -0,0 +1,148 @@
+// Package guard matches a set of changed files against every map that governs them
+// and decides whether the change needs sign-off from owning teams.
+package guard
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+// Kind names the boundary a changed file touched.
+type Kind string
+
+const (
+	KindCritical Kind = "critical"
+	KindModel    Kind = "model"
+	KindConfig   Kind = "config"
+)
+
+// ParseKinds parses a comma-separated kind list; "any" means all kinds and "none" means none.
+func ParseKinds(s string) ([]Kind, error) {
+	var out []Kind
+	for _, part := range strings.Split(s, ",") {
+		switch k := strings.ToLower(strings.TrimSpace(part)); k {
+		case "", "none":
+		case "any":
+			return []Kind{KindCritical, KindModel, KindConfig}, nil
+		case string(KindCritical), string(KindModel), string(KindConfig):
+			out = append(out, Kind(k))
+		default:
+			return nil, fmt.Errorf("unknown kind %q (expected critical|model|config|any|none)", k)
+		}
+	}
+	return out, nil
+}
+
+// Team is the review key for a map: its ownership.team, or its system name when unowned.
+func Team(m aimap.Located) string {
+	if t := strings.TrimSpace(m.Map.Ownership.Team); t != "" {
+		return t
+	}
+	if n := strings.TrimSpace(m.Map.System.Name); n != "" {
+		return "unowned:" + n
+	}
+	return "unowned:" + m.Path
+}
+
+// Touch records one changed file falling under one boundary of one map.
+type Touch struct {
+	File    string
+	Kind    Kind
+	Pattern string
+	MapPath string
+	Team    string
+}
+
+type Report struct {
+	Files   []string
+	Touches []Touch
+}
+
+// Check matches repo-relative changed files against every map governing them.
+// A map governs every file in its directory tree, so nested maps and their
+// ancestors both apply. maps may hold several versions of the same map (e.g.
+// before and after the change); identical touches are reported once.
+func Check(maps []aimap.Located, files []string) Report {
+	rep := Report{Files: append([]string(nil), files...)}
+	sort.Strings(rep.Files)
+	seen := map[Touch]bool{}
+	for _, f := range rep.Files {
+		f = aimap.CleanPath(f)
+		for _, m := range maps {
+			rel, ok := within(m.Dir, f)
+			if !ok {
+				continue
+			}
+			add := func(kind Kind, patterns []string) {
+				for _, p := range patterns {
+					if aimap.MatchPath(p, rel) {
+						t := Touch{File: f, Kind: kind, Pattern: p, MapPath: m.Path, Team: Team(m)}
+						if !seen[t] {
+							seen[t] = true
+							rep.Touches = append(rep.Touches, t)
+						}
+						return
+					}
+				}
+			}
+			add(KindCritical, m.Map.Boundaries.Critical)
+			add(KindModel, m.Map.Boundaries.Models)
+			add(KindConfig, m.Map.Runtime.ConfigPaths)
+		}
+	}
+	return rep
+}
+
+// Teams returns the distinct teams whose boundaries were touched, sorted.
+func (r Report) Teams() []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, t := range r.Touches {
+		if !seen[t.Team] {
+			seen[t.Team] = true
+			out = append(out, t.Team)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Policy decides which touches block a change.
+type Policy struct {
+	// FailOn lists the kinds that require sign-off.
+	FailOn []Kind
+	// Approved lists teams that signed off; "*" approves every team.
+	Approved []string
+}
+
+// Violations returns touches of a blocking kind whose team has not approved.
+func (r Report) Violations(p Policy) []Touch {
+	approved := map[string]bool{}
+	for _, a := range p.Approved {
+		approved[strings.TrimSpace(a)] = true
+	}
+	blocking := map[Kind]bool{}
+	for _, k := range p.FailOn {
+		blocking[k] = true
+	}
+	var out []Touch
+	for _, t := range r.Touches {
+		if blocking[t.Kind] && !approved[t.Team] && !approved["*"] {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func within(dir, file string) (string, bool) {
+	if dir == "." {
+		return file, true
+	}
+	if strings.HasPrefix(file, dir+"/") {
+		return strings.TrimPrefix(file, dir+"/"), true
+	}
+	return "", false
+}
@@ -0,0 +1,39 @@
+package guard
+
+import (
+	"testing"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+func mustParse(t *testing.T, s string) *aimap.Map {
+	t.Helper()
+	m, err := aimap.Parse([]byte(s))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	return m
+}
+
+func TestCheckAndViolations(t *testing.T) {
+	maps := []aimap.Located{
+		{Path: ".ai-map.yaml", Dir: ".", Map: mustParse(t, "system: {name: root}\nboundaries: {critical: [src/core]}\nownership: {team: platform}\n")},
+		{Path: "svc/b/.ai-map.yaml", Dir: "svc/b", Map: mustParse(t, "system: {name: b}\nboundaries: {critical: [core], models: [models]}\n")},
+	}
+	rep := Check(maps, []string{"svc/b/core/x.go", "src/core/a.go", "README.md", "svc/b/models/m.go"})
+
+	if got := len(rep.Touches); got != 3 {
+		t.Fatalf("got %d touches, want 3: %#v", got, rep.Touches)
+	}
+	if teams := rep.Teams(); len(teams) != 2 || teams[0] != "platform" || teams[1] != "unowned:b" {
+		t.Fatalf("unexpected teams %v", teams)
+	}
+
+	v := rep.Violations(Policy{FailOn: []Kind{KindCritical}, Approved: []string{"platform"}})
+	if len(v) != 1 || v[0].File != "svc/b/core/x.go" {
+		t.Fatalf("unexpected violations %#v", v)
+	}
+	if v := rep.Violations(Policy{FailOn: []Kind{KindCritical, KindModel}, Approved: []string{"*"}}); len(v) != 0 {
+		t.Fatalf("expected wildcard approval to clear violations, got %#v", v)
+	}
+}
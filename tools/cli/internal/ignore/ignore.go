@@ -0,0 +1,133 @@
+// Package ignore implements gitignore-style pattern matching, as used by
+// .gitignore, .git/info/exclude and CODEOWNERS.
+package ignore
+
+import (
+	"bufio"
+	"bytes"
+	"path"
+	"strings"
+)
+
+// Rule is one compiled pattern line.
+type Rule struct {
+	// Pattern is the pattern as written (without a leading "!").
+	Pattern string
+	Negate  bool
+	DirOnly bool
+	segs    []string
+}
+
+// ParseRule compiles a single pattern. It returns false for blank lines and comments.
+func ParseRule(line string) (Rule, bool) {
+	line = strings.TrimRight(line, "\r")
+	if !strings.HasSuffix(line, "\\ ") {
+		line = strings.TrimRight(line, " \t")
+	}
+	if line == "" || strings.HasPrefix(line, "#") {
+		return Rule{}, false
+	}
+	r := Rule{}
+	if strings.HasPrefix(line, "!") {
+		r.Negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, "\\")
+	r.Pattern = line
+	if strings.HasSuffix(line, "/") {
+		r.DirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return Rule{}, false
+	}
+	// A slash anywhere but the end anchors the pattern to the base directory;
+	// otherwise it matches at any depth.
+	anchored := strings.Contains(line, "/")
+	line = strings.TrimPrefix(line, "/")
+	r.segs = strings.Split(line, "/")
+	if !anchored {
+		r.segs = append([]string{"**"}, r.segs...)
+	}
+	return r, true
+}
+
+// Match reports whether the rule matches rel (slash-separated, relative to the
+// rule's base directory) exactly, without considering parent directories.
+func (r Rule) Match(rel string, isDir bool) bool {
+	if r.DirOnly && !isDir {
+		return false
+	}
+	return matchSegs(r.segs, strings.Split(rel, "/"))
+}
+
+// MatchTree reports whether the rule matches rel or any of its parent directories.
+func (r Rule) MatchTree(rel string, isDir bool) bool {
+	segs := strings.Split(rel, "/")
+	for i := 1; i < len(segs); i++ {
+		if r.Match(strings.Join(segs[:i], "/"), true) {
+			return true
+		}
+	}
+	return r.Match(rel, isDir)
+}
+
+func matchSegs(pat, segs []string) bool {
+	for len(pat) > 0 {
+		if pat[0] == "**" {
+			if len(pat) == 1 {
+				return len(segs) > 0
+			}
+			for i := 0; i <= len(segs); i++ {
+				if matchSegs(pat[1:], segs[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(segs) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pat[0], segs[0]); err != nil || !ok {
+			return false
+		}
+		pat, segs = pat[1:], segs[1:]
+	}
+	return len(segs) == 0
+}
+
+// Matcher is an ordered rule list rooted at Base (slash path relative to the walk
+// root; "" for the root). Later rules override earlier ones.
+type Matcher struct {
+	Base  string
+	Rules []Rule
+}
+
+// Parse compiles a gitignore-format file.
+func Parse(content []byte, base string) Matcher {
+	m := Matcher{Base: strings.Trim(base, "/")}
+	sc := bufio.NewScanner(bytes.NewReader(content))
+	for sc.Scan() {
+		if r, ok := ParseRule(sc.Text()); ok {
+			m.Rules = append(m.Rules, r)
+		}
+	}
+	return m
+}
+
+// Decide returns (ignored, decided) for rel, a slash path relative to the walk root.
+// decided is false when no rule in this matcher applies.
+func (m Matcher) Decide(rel string, isDir bool) (ignored, decided bool) {
+	if m.Base != "" {
+		if !strings.HasPrefix(rel, m.Base+"/") {
+			return false, false
+		}
+		rel = strings.TrimPrefix(rel, m.Base+"/")
+	}
+	for i := len(m.Rules) - 1; i >= 0; i-- {
+		if m.Rules[i].Match(rel, isDir) {
+			return !m.Rules[i].Negate, true
+		}
+	}
+	return false, false
+}
@@ -0,0 +1,53 @@
+package ignore
+
+import "testing"
+
+func TestRuleMatch(t *testing.T) {
+	cases := []struct {
+		pattern string
+		path    string
+		isDir   bool
+		tree    bool
+		want    bool
+	}{
+		{"*.log", "a/b/x.log", false, false, true},
+		{"/build", "build", true, false, true},
+		{"/build", "src/build", true, false, false},
+		{"build/", "src/build", false, false, false},
+		{"build/", "src/build", true, false, true},
+		{"docs/*.md", "docs/a.md", false, false, true},
+		{"docs/*.md", "x/docs/a.md", false, false, false},
+		{"**/gen", "a/b/gen", true, false, true},
+		{"a/**/z", "a/z", false, false, true},
+		{"a/**/z", "a/b/c/z", false, false, true},
+		{"logs/**", "logs/x/y", false, false, true},
+		{"/src/core", "src/core/engine/x.go", false, true, true},
+		{"/src/core", "src/corex/a.go", false, true, false},
+	}
+	for _, c := range cases {
+		r, ok := ParseRule(c.pattern)
+		if !ok {
+			t.Fatalf("ParseRule(%q) failed", c.pattern)
+		}
+		got := r.Match(c.path, c.isDir)
+		if c.tree {
+			got = r.MatchTree(c.path, c.isDir)
+		}
+		if got != c.want {
+			t.Errorf("%q vs %q: got %v, want %v", c.pattern, c.path, got, c.want)
+		}
+	}
+}
+
+func TestMatcherNegationAndBase(t *testing.T) {
+	m := Parse([]byte("# comment\n*.yaml\n!keep.yaml\n"), "sub")
+	if ign, ok := m.Decide("sub/a/x.yaml", false); !ok || !ign {
+		t.Errorf("expected sub/a/x.yaml ignored")
+	}
+	if ign, ok := m.Decide("sub/keep.yaml", false); !ok || ign {
+		t.Errorf("expected sub/keep.yaml re-included")
+	}
+	if _, ok := m.Decide("other/x.yaml", false); ok {
+		t.Errorf("rules must not apply outside their base")
+	}
+}
@@ -3,30 +3,89 @@ package input
 import (
 	"errors"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
+	"runtime"
 	"sort"
 	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/discover"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/git"
+	"github.com/spf13/pflag"
 )
 
 const (
-	// MaxYAMLBytes is a safety cap for reading YAML inputs.
-	MaxYAMLBytes int64 = 2 << 20 // 2 MiB
+	// DefaultMaxYAMLBytes is the default safety cap for reading YAML inputs.
+	DefaultMaxYAMLBytes int64 = 2 << 20 // 2 MiB
+
+	// Stdin is the path argument that reads a document from standard input.
+	Stdin = "-"
+	// StdinName labels standard input in diagnostics.
+	StdinName = "<stdin>"
 )
 
+// MaxYAMLBytes caps every YAML read. It is set once at startup from --max-bytes or
+// the tool config's max_bytes.
+var MaxYAMLBytes = DefaultMaxYAMLBytes
+
+// stdin is swapped out in tests.
+var stdin io.Reader = os.Stdin
+
 type Selection struct {
 	Dir       string
 	Recursive bool
+	// Discover finds map files by name under Dir (default ".") recursively, honouring
+	// .gitignore and .git/info/exclude, instead of taking every *.yml|*.yaml.
+	Discover bool
+	// MapName is the file name Discover looks for (default .ai-map.yaml).
+	MapName string
+	// Include and Exclude are gitignore-style globs relative to Dir (discovery only).
+	Include []string
+	Exclude []string
+}
+
+// AddFlags registers the shared input selection flags on a command.
+func AddFlags(fs *pflag.FlagSet, sel *Selection) {
+	fs.StringVar(&sel.Dir, "dir", "", "Directory to scan for *.yml|*.yaml (non-recursive by default)")
+	fs.BoolVar(&sel.Recursive, "recursive", false, "Scan directories recursively (off by default)")
+	fs.BoolVar(&sel.Discover, "discover", false, "Find map files by name under --dir (default .), honouring .gitignore")
+	fs.StringVar(&sel.MapName, "map-name", discover.DefaultName, "Map file name for --discover")
+	fs.StringArrayVar(&sel.Include, "include", nil, "With --discover: only paths matching this glob (repeatable)")
+	fs.StringArrayVar(&sel.Exclude, "exclude", nil, "With --discover: skip paths matching this glob (repeatable)")
 }
 
 func SelectFiles(sel Selection, args []string) ([]string, error) {
 	if sel.Dir != "" && len(args) > 0 {
 		return nil, fmt.Errorf("provide either --dir or file paths, not both")
 	}
+	if !sel.Discover && (len(sel.Include) > 0 || len(sel.Exclude) > 0) {
+		return nil, fmt.Errorf("--include/--exclude require --discover")
+	}
+	if sel.Discover && sel.Recursive {
+		return nil, fmt.Errorf("--recursive does not apply to --discover, which always searches the whole tree")
+	}
 
 	var files []string
-	if sel.Dir != "" {
+	if sel.Discover {
+		if len(args) > 0 {
+			return nil, fmt.Errorf("provide either --discover or file paths, not both")
+		}
+		dir := sel.Dir
+		if dir == "" {
+			dir = "."
+		}
+		opt := discover.Options{Include: sel.Include, Exclude: sel.Exclude}
+		if sel.MapName != "" {
+			opt.Names = []string{sel.MapName}
+		}
+		found, err := discover.Find(dir, opt)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, found...)
+	} else if sel.Dir != "" {
 		root, err := filepath.Abs(sel.Dir)
 		if err != nil {
 			return nil, fmt.Errorf("invalid --dir: %w", err)
@@ -37,10 +96,23 @@ func SelectFiles(sel Selection, args []string) ([]string, error) {
 		}
 		files = append(files, entries...)
 	} else {
+		var sawStdin bool
 		for _, p := range args {
 			if strings.TrimSpace(p) == "" {
 				continue
 			}
+			if p == Stdin {
+				if sawStdin {
+					return nil, fmt.Errorf("%q (stdin) can only be given once", Stdin)
+				}
+				sawStdin = true
+				files = append(files, p)
+				continue
+			}
+			if _, _, ok := SplitRev(p); ok {
+				files = append(files, p)
+				continue
+			}
 			abs, err := filepath.Abs(p)
 			if err != nil {
 				return nil, fmt.Errorf("invalid path %q: %w", p, err)
@@ -52,6 +124,9 @@ func SelectFiles(sel Selection, args []string) ([]string, error) {
 	sort.Strings(files)
 
 	for _, f := range files {
+		if !IsFile(f) {
+			continue
+		}
 		info, err := os.Stat(f)
 		if err != nil {
 			return nil, fmt.Errorf("cannot stat %q: %w", f, err)
@@ -64,8 +139,63 @@ func SelectFiles(sel Selection, args []string) ([]string, error) {
 	return files, nil
 }
 
+// SplitRev splits a REV:path argument. An existing file whose name contains a
+// colon is never treated as a revision, nor is a Windows drive letter.
+func SplitRev(arg string) (rev, path string, ok bool) {
+	i := strings.Index(arg, ":")
+	if i <= 0 || i == len(arg)-1 {
+		return "", "", false
+	}
+	if i == 1 && runtime.GOOS == "windows" {
+		return "", "", false
+	}
+	if _, err := os.Lstat(arg); err == nil {
+		return "", "", false
+	}
+	return arg[:i], arg[i+1:], true
+}
+
+// IsFile reports whether a selected input is a plain file on disk (not stdin or REV:path).
+func IsFile(arg string) bool {
+	if arg == Stdin {
+		return false
+	}
+	_, _, ok := SplitRev(arg)
+	return !ok
+}
+
+// DisplayName is how an input is named in diagnostics.
+func DisplayName(arg string) string {
+	if arg == Stdin {
+		return StdinName
+	}
+	return arg
+}
+
+// Read returns the contents of a selected input: a file, stdin ("-"), or a file at
+// a git revision ("REV:path", via the local git binary). All are capped at maxBytes.
+func Read(arg string, maxBytes int64) ([]byte, error) {
+	if arg == Stdin {
+		if maxBytes <= 0 {
+			return nil, errors.New("maxBytes must be > 0")
+		}
+		b, err := io.ReadAll(io.LimitReader(stdin, maxBytes+1))
+		if err != nil {
+			return nil, fmt.Errorf("cannot read stdin: %w", err)
+		}
+		if int64(len(b)) > maxBytes {
+			return nil, fmt.Errorf("stdin too large (> %d bytes)", maxBytes)
+		}
+		return b, nil
+	}
+	if rev, path, ok := SplitRev(arg); ok {
+		return git.Show(rev, path, maxBytes)
+	}
+	return ReadFileWithLimit(arg, maxBytes)
+}
+
 func EnsureSelected(sel Selection, inputs []string) error {
-	if sel.Dir == "" && len(inputs) == 0 {
+	if sel.Dir == "" && !sel.Discover && len(inputs) == 0 {
 		return errors.New("no input files provided")
 	}
 	return nil
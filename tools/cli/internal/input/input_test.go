@@ -0,0 +1,95 @@
+package input
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestReadStdin(t *testing.T) {
+	old := stdin
+	defer func() { stdin = old }()
+
+	stdin = strings.NewReader("version: 1\n")
+	b, err := Read(Stdin, 64)
+	if err != nil || string(b) != "version: 1\n" {
+		t.Fatalf("Read(-) = %q, %v", b, err)
+	}
+
+	stdin = strings.NewReader(strings.Repeat("x", 65))
+	if _, err := Read(Stdin, 64); err == nil {
+		t.Fatal("expected oversized stdin to be rejected")
+	}
+}
+
+func TestSplitRev(t *testing.T) {
+	dir := t.TempDir()
+	colon := filepath.Join(dir, "a:b.yaml")
+	if err := os.WriteFile(colon, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		arg       string
+		rev, path string
+		ok        bool
+	}{
+		{"HEAD:.ai-map.yaml", "HEAD", ".ai-map.yaml", true},
+		{"v1.2.0~1:svc/a/.ai-map.yaml", "v1.2.0~1", "svc/a/.ai-map.yaml", true},
+		{"plain.yaml", "", "", false},
+		{":index.yaml", "", "", false},
+		{"HEAD:", "", "", false},
+		{colon, "", "", false},
+	} {
+		rev, path, ok := SplitRev(c.arg)
+		if rev != c.rev || path != c.path || ok != c.ok {
+			t.Errorf("SplitRev(%q) = %q, %q, %v", c.arg, rev, path, ok)
+		}
+	}
+}
+
+func TestReadRevision(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) {
+		cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	git("init", "-q")
+	p := filepath.Join(dir, ".ai-map.yaml")
+	if err := os.WriteFile(p, []byte("version: 1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	git("add", ".")
+	git("commit", "-q", "--no-verify", "-m", "init")
+	if err := os.WriteFile(p, []byte("version: 2\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := Read("HEAD:"+p, MaxYAMLBytes)
+	if err != nil || string(b) != "version: 1\n" {
+		t.Fatalf("Read(HEAD:...) = %q, %v", b, err)
+	}
+	if _, err := Read("--output=x:"+p, MaxYAMLBytes); err == nil {
+		t.Fatal("expected option-like revision to be rejected")
+	}
+}
+
+func TestSelectFilesRejectsConflictingFlags(t *testing.T) {
+	for _, tc := range []struct {
+		sel  Selection
+		want string
+	}{
+		{Selection{Include: []string{"svc/**"}}, "require --discover"},
+		{Selection{Discover: true, Recursive: true}, "--recursive does not apply to --discover"},
+	} {
+		if _, err := SelectFiles(tc.sel, nil); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("SelectFiles(%+v) error = %v, want %q", tc.sel, err, tc.want)
+		}
+	}
+}
@@ -2,8 +2,14 @@ package lint
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/detect"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/git"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/schema"
 	"gopkg.in/yaml.v3"
 )
 
@@ -14,10 +20,63 @@ const (
 	SeverityWarn  Severity = "warn"
 )
 
+// SeverityOff disables a rule in Options.Rules.
+const SeverityOff Severity = "off"
+
+// Rule IDs, as used by `lint --rule NAME=SEVERITY` and the tool config's lint.rules.
+const (
+	RuleVersion              = "version"
+	RuleSystem               = "system"
+	RuleSystemName           = "system-name"
+	RuleSystemNameWhitespace = "system-name-whitespace"
+	RuleSystemType           = "system-type"
+	RuleOpenAPIStale         = "openapi-stale"
+	RuleComposeMismatch      = "compose-mismatch"
+	RuleDeploysViaMismatch   = "deploys-via-mismatch"
+)
+
+// Rules lists every rule ID.
+func Rules() []string {
+	return []string{RuleVersion, RuleSystem, RuleSystemName, RuleSystemNameWhitespace, RuleSystemType, RuleOpenAPIStale, RuleComposeMismatch, RuleDeploysViaMismatch}
+}
+
+// ParseSeverity parses a rule setting: off, warn or error.
+func ParseSeverity(s string) (Severity, error) {
+	switch v := Severity(strings.ToLower(strings.TrimSpace(s))); v {
+	case SeverityOff, SeverityWarn, SeverityError:
+		return v, nil
+	default:
+		return "", fmt.Errorf("unknown severity %q (expected off|warn|error)", s)
+	}
+}
+
+// ParseRuleSetting parses NAME=SEVERITY.
+func ParseRuleSetting(s string) (string, Severity, error) {
+	name, sev, ok := strings.Cut(s, "=")
+	if !ok {
+		return "", "", fmt.Errorf("invalid rule setting %q (expected NAME=off|warn|error)", s)
+	}
+	name = strings.TrimSpace(name)
+	known := false
+	for _, r := range Rules() {
+		known = known || r == name
+	}
+	if !known {
+		return "", "", fmt.Errorf("unknown lint rule %q (expected one of %s)", name, strings.Join(Rules(), ", "))
+	}
+	v, err := ParseSeverity(sev)
+	if err != nil {
+		return "", "", fmt.Errorf("rule %s: %w", name, err)
+	}
+	return name, v, nil
+}
+
 type Issue struct {
 	Severity Severity
 	Message  string
 	Path     string
+	// Rule is the rule ID; empty for parse errors, which cannot be disabled.
+	Rule string
 }
 
 type Result struct {
@@ -33,8 +92,43 @@ func (r Result) HasErrors() bool {
 	return false
 }
 
+// RulesVersion must be bumped whenever a rule is added or changes its output, so
+// cached lint results are invalidated.
+const RulesVersion = 5
+
+// Fingerprint identifies the rule set, including the schema registry the version
+// check consults.
+func Fingerprint() string {
+	return fmt.Sprintf("rules-v%d/%s", RulesVersion, schema.Hash())
+}
+
 type Options struct {
 	MaxBytes int64
+	// Rules overrides the severity of rules by ID; SeverityOff drops their issues.
+	Rules map[string]Severity
+}
+
+func (o Options) off(rule string) bool {
+	return o.Rules[rule] == SeverityOff
+}
+
+// Apply applies the rule overrides to a result. Results are cached before
+// overrides, so rule settings need not be part of the cache key.
+func (o Options) Apply(r Result) Result {
+	if len(o.Rules) == 0 {
+		return r
+	}
+	out := Result{}
+	for _, is := range r.Issues {
+		if sev, ok := o.Rules[is.Rule]; ok && is.Rule != "" {
+			if sev == SeverityOff {
+				continue
+			}
+			is.Severity = sev
+		}
+		out.Issues = append(out.Issues, is)
+	}
+	return out
 }
 
 func LintYAMLBytes(b []byte) Result {
@@ -66,39 +160,45 @@ func LintYAMLBytes(b []byte) Result {
 
 	// Required: version (spec says required)
 	if _, ok := m["version"]; !ok {
-		issues = append(issues, Issue{Severity: SeverityError, Path: "version", Message: "missing required field"})
+		issues = append(issues, Issue{Severity: SeverityError, Path: "version", Message: "missing required field", Rule: RuleVersion})
 	} else {
 		switch v := m["version"].(type) {
 		case int, int64, uint64, uint, float64:
-			// ok-ish; we don't enforce exact '1' here (spec version might evolve).
+			// The accepted set follows the embedded schema registry.
+			ver, err := schema.VersionOf(m)
+			if err != nil {
+				issues = append(issues, Issue{Severity: SeverityError, Path: "version", Message: strings.TrimPrefix(err.Error(), "version "), Rule: RuleVersion})
+			} else if !schema.Supported(ver) {
+				issues = append(issues, Issue{Severity: SeverityError, Path: "version", Message: schema.UnsupportedError(ver).Error(), Rule: RuleVersion})
+			}
 		default:
-			issues = append(issues, Issue{Severity: SeverityError, Path: "version", Message: fmt.Sprintf("must be a number, got %T", v)})
+			issues = append(issues, Issue{Severity: SeverityError, Path: "version", Message: fmt.Sprintf("must be a number, got %T", v), Rule: RuleVersion})
 		}
 	}
 
 	// Required: system (spec says required)
 	sys, ok := m["system"]
 	if !ok {
-		issues = append(issues, Issue{Severity: SeverityError, Path: "system", Message: "missing required field"})
+		issues = append(issues, Issue{Severity: SeverityError, Path: "system", Message: "missing required field", Rule: RuleSystem})
 	} else {
 		sm, ok := asStringMap(sys)
 		if !ok {
-			issues = append(issues, Issue{Severity: SeverityError, Path: "system", Message: "must be an object"})
+			issues = append(issues, Issue{Severity: SeverityError, Path: "system", Message: "must be an object", Rule: RuleSystem})
 		} else {
 			// system.name: required, non-empty, slug-ish
 			if name, ok := sm["name"].(string); !ok || strings.TrimSpace(name) == "" {
-				issues = append(issues, Issue{Severity: SeverityError, Path: "system.name", Message: "missing or empty"})
+				issues = append(issues, Issue{Severity: SeverityError, Path: "system.name", Message: "missing or empty", Rule: RuleSystemName})
 			} else if strings.ContainsAny(name, " \t\r\n") {
-				issues = append(issues, Issue{Severity: SeverityWarn, Path: "system.name", Message: "should not contain whitespace"})
+				issues = append(issues, Issue{Severity: SeverityWarn, Path: "system.name", Message: "should not contain whitespace", Rule: RuleSystemNameWhitespace})
 			}
 
 			// system.type: if present, must be one of known values
 			if tRaw, ok := sm["type"]; ok {
 				t, ok := tRaw.(string)
 				if !ok {
-					issues = append(issues, Issue{Severity: SeverityError, Path: "system.type", Message: "must be a string"})
+					issues = append(issues, Issue{Severity: SeverityError, Path: "system.type", Message: "must be a string", Rule: RuleSystemType})
 				} else if !isAllowedType(t) {
-					issues = append(issues, Issue{Severity: SeverityWarn, Path: "system.type", Message: "unknown value (expected one of service|webapp|library|infra|monorepo)"})
+					issues = append(issues, Issue{Severity: SeverityWarn, Path: "system.type", Message: "unknown value (expected one of service|webapp|library|infra|monorepo)", Rule: RuleSystemType})
 				}
 			}
 		}
@@ -107,6 +207,71 @@ func LintYAMLBytes(b []byte) Result {
 	return Result{Issues: issues}
 }
 
+// LintFile runs the rules that look at files around the map at mapPath rather
+// than its content alone. They depend on the filesystem, so their results are
+// never cached; callers append them to LintYAMLBytes' result before opt.Apply.
+// Rules that opt turns off are not run at all.
+func LintFile(mapPath string, b []byte, opt Options) Result {
+	m, err := aimap.Parse(b)
+	if err != nil {
+		return Result{}
+	}
+	var issues []Issue
+
+	// The OpenAPI description changed after the map was last touched, so the
+	// entrypoints imported from it may be out of date.
+	if spec := m.Ownership.Docs.OpenAPI; spec != "" && !strings.Contains(spec, "://") && !filepath.IsAbs(spec) && !opt.off(RuleOpenAPIStale) {
+		if newer(filepath.Join(filepath.Dir(mapPath), filepath.FromSlash(spec)), mapPath) {
+			issues = append(issues, Issue{
+				Severity: SeverityWarn,
+				Path:     "ownership.docs.openapi",
+				Message:  spec + " was changed after the map; re-run `ai-map import --from openapi` to refresh entrypoints",
+				Rule:     RuleOpenAPIStale,
+			})
+		}
+	}
+
+	// Files next to the map (compose files, CI workflows, ...) contradict what it declares.
+	// Detectors that cannot read their inputs stay silent; `ai-map detect` reports why.
+	var detectors []string
+	for d, rule := range detectorRules {
+		if !opt.off(rule) {
+			detectors = append(detectors, d)
+		}
+	}
+	if len(detectors) == 0 {
+		return Result{Issues: issues}
+	}
+	if r, err := detect.Detect(filepath.Dir(mapPath), detect.Options{Only: detectors}); err == nil {
+		for _, mm := range detect.Compare(m, r) {
+			if rule, ok := detectorRules[mm.Detector]; ok {
+				issues = append(issues, Issue{Severity: SeverityWarn, Path: mm.Field, Message: mm.Message, Rule: rule})
+			}
+		}
+	}
+	return Result{Issues: issues}
+}
+
+// newer reports whether file a changed after file b. Inside git it compares
+// the files' last commit times, which survive clones and checkouts; otherwise
+// (or for uncommitted files) their modification times.
+func newer(a, b string) bool {
+	ta, errA := git.LastCommitTime(a)
+	tb, errB := git.LastCommitTime(b)
+	if errA == nil && errB == nil {
+		return ta.After(tb)
+	}
+	sa, errA := os.Stat(a)
+	sb, errB := os.Stat(b)
+	return errA == nil && errB == nil && sa.ModTime().After(sb.ModTime())
+}
+
+// detectorRules maps detect.Detector names to the rules reporting their mismatches.
+var detectorRules = map[string]string{
+	detect.DetectorCompose: RuleComposeMismatch,
+	detect.DetectorDeploy:  RuleDeploysViaMismatch,
+}
+
 func asStringMap(v any) (map[string]any, bool) {
 	switch m := v.(type) {
 	case map[string]any:
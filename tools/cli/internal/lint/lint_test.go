@@ -0,0 +1,123 @@
+package lint
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestLintFileOpenAPIStale(t *testing.T) {
+	dir := t.TempDir()
+	mapPath := filepath.Join(dir, ".ai-map.yaml")
+	specPath := filepath.Join(dir, "api", "openapi.yaml")
+	body := []byte("version: 1\nsystem:\n  name: users\nownership:\n  docs:\n    openapi: api/openapi.yaml\n")
+	if err := os.MkdirAll(filepath.Dir(specPath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{mapPath, specPath} {
+		if err := os.WriteFile(p, body, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-time.Hour)
+
+	if err := os.Chtimes(specPath, old, old); err != nil {
+		t.Fatal(err)
+	}
+	if res := LintFile(mapPath, body, Options{}); len(res.Issues) != 0 {
+		t.Fatalf("spec older than map: unexpected issues %v", res.Issues)
+	}
+
+	if err := os.Chtimes(mapPath, old.Add(-time.Hour), old.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	res := LintFile(mapPath, body, Options{})
+	if len(res.Issues) != 1 || res.Issues[0].Rule != RuleOpenAPIStale || res.Issues[0].Severity != SeverityWarn {
+		t.Fatalf("spec newer than map: got %v", res.Issues)
+	}
+}
+
+func TestLintFileOpenAPIStaleUsesCommitTimes(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	body := []byte("version: 1\nsystem:\n  name: users\nownership:\n  docs:\n    openapi: openapi.yaml\n")
+	mapPath := filepath.Join(dir, ".ai-map.yaml")
+	specPath := filepath.Join(dir, "openapi.yaml")
+	commit := func(date string, files map[string]string) {
+		t.Helper()
+		for p, content := range files {
+			if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+				t.Fatal(err)
+			}
+		}
+		for _, args := range [][]string{{"add", "-A"}, {"commit", "-q", "-m", date}} {
+			cmd := exec.Command("git", append([]string{"-C", dir, "-c", "user.name=t", "-c", "user.email=t@example.com"}, args...)...)
+			cmd.Env = append(os.Environ(), "GIT_AUTHOR_DATE="+date, "GIT_COMMITTER_DATE="+date)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Fatalf("git %v: %v\n%s", args, err, out)
+			}
+		}
+	}
+	if out, err := exec.Command("git", "-C", dir, "init", "-q").CombinedOutput(); err != nil {
+		t.Fatalf("git init: %v\n%s", err, out)
+	}
+	commit("2024-01-01T00:00:00Z", map[string]string{mapPath: string(body), specPath: "openapi: 3.0.0\n"})
+
+	// A checkout leaves arbitrary mtimes; committed files are judged by their history.
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(mapPath, past, past); err != nil {
+		t.Fatal(err)
+	}
+	if res := LintFile(mapPath, body, Options{}); len(res.Issues) != 0 {
+		t.Fatalf("committed together: unexpected issues %v", res.Issues)
+	}
+
+	commit("2024-02-01T00:00:00Z", map[string]string{specPath: "openapi: 3.0.1\n"})
+	res := LintFile(mapPath, body, Options{})
+	if len(res.Issues) != 1 || res.Issues[0].Rule != RuleOpenAPIStale {
+		t.Fatalf("spec committed after map: got %v", res.Issues)
+	}
+}
+
+func TestLintFileDetectorRules(t *testing.T) {
+	dir := t.TempDir()
+	mapPath := filepath.Join(dir, ".ai-map.yaml")
+	body := []byte("version: 1\nsystem:\n  name: shop\nruntime:\n  environment: container\n  deploys_via: helm\n")
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(".ai-map.yaml", string(body))
+	write("compose.yaml", "services:\n  app:\n    build: .\n  db:\n    image: postgres:16\n")
+	write("cdk.json", "{}\n")
+
+	rules := func(res Result) map[string]int {
+		got := map[string]int{}
+		for _, is := range res.Issues {
+			got[is.Rule]++
+		}
+		return got
+	}
+	got := rules(LintFile(mapPath, body, Options{}))
+	if got[RuleComposeMismatch] != 1 || got[RuleDeploysViaMismatch] != 1 {
+		t.Fatalf("issues by rule = %v, want one compose-mismatch and one deploys-via-mismatch", got)
+	}
+
+	// A rule that is off skips its detector: the broken compose file would
+	// otherwise silence every detector.
+	write("compose.yaml", "services: [\n")
+	got = rules(LintFile(mapPath, body, Options{Rules: map[string]Severity{RuleComposeMismatch: SeverityOff}}))
+	if len(got) != 1 || got[RuleDeploysViaMismatch] != 1 {
+		t.Fatalf("compose-mismatch off: issues by rule = %v", got)
+	}
+	off := Options{Rules: map[string]Severity{RuleComposeMismatch: SeverityOff, RuleDeploysViaMismatch: SeverityOff}}
+	if res := LintFile(mapPath, body, off); len(res.Issues) != 0 {
+		t.Fatalf("both rules off: got %v", res.Issues)
+	}
+}
@@ -0,0 +1,146 @@
+// Package llmstxt renders a map as an llms.txt file (https://llmstxt.org): an H1
+// with the system name, a blockquote summary, notes without headings, then H2
+// sections of links. Links are relative to the map's directory, where the file
+// belongs, unless a base URL is given.
+package llmstxt
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+// FileName is the conventional name, at a repository's root.
+const FileName = "llms.txt"
+
+// Options tunes Generate.
+type Options struct {
+	// BaseURL, when set, is prefixed to repo-relative link targets.
+	BaseURL string
+}
+
+// Generate renders m. The output depends only on m and opt, so a committed
+// llms.txt can be compared byte for byte with a fresh one.
+func Generate(m *aimap.Map, opt Options) []byte {
+	var b bytes.Buffer
+	name := m.System.Name
+	if name == "" {
+		name = "Unnamed system"
+	}
+	fmt.Fprintf(&b, "# %s\n\n", name)
+	fmt.Fprintf(&b, "> %s\n\n", summary(m))
+	fmt.Fprintf(&b, "Generated from %s by `ai-map export --format llms-txt`; edit the map, not this file.\n", aimap.FileName)
+
+	// Critical areas are flagged up front, before any link an agent might follow.
+	if len(m.Boundaries.Critical) > 0 {
+		review := "the owners"
+		if m.Ownership.Team != "" {
+			review = m.Ownership.Team
+		}
+		fmt.Fprintf(&b, "\nCritical areas (changes need review by %s):\n\n", review)
+		for _, c := range m.Boundaries.Critical {
+			fmt.Fprintf(&b, "- `%s`\n", c)
+		}
+	}
+	if ignore := m.AIFlow().Ignore; len(ignore) > 0 {
+		b.WriteString("\nDo not read or edit:\n\n")
+		for _, p := range ignore {
+			fmt.Fprintf(&b, "- `%s`\n", p)
+		}
+	}
+
+	var eps []string
+	for _, proto := range m.Boundaries.Protocols() {
+		for _, e := range m.Boundaries.Entrypoints[proto] {
+			eps = append(eps, link(e, e, opt)+": "+proto+" entrypoint"+flag(m, e))
+		}
+	}
+	section(&b, "Entrypoints", eps)
+
+	var models []string
+	for _, p := range m.Boundaries.Models {
+		models = append(models, link(p, p, opt)+flag(m, p))
+	}
+	section(&b, "Models", models)
+
+	var docs []string
+	for _, d := range []struct{ title, path string }{
+		{"Runbook", m.Ownership.Docs.Runbook},
+		{"Architecture decision records", m.Ownership.Docs.ADR},
+		{"OpenAPI description", m.Ownership.Docs.OpenAPI},
+	} {
+		if strings.TrimSpace(d.path) != "" {
+			docs = append(docs, link(d.title, d.path, opt))
+		}
+	}
+	section(&b, "Docs", docs)
+
+	// Configuration is useful but skippable: the llms.txt "Optional" section.
+	var optional []string
+	for _, p := range m.Runtime.ConfigPaths {
+		optional = append(optional, link(p, p, opt)+": configuration")
+	}
+	section(&b, "Optional", optional)
+	return b.Bytes()
+}
+
+// summary describes the system in one sentence or two.
+func summary(m *aimap.Map) string {
+	s := m.System.Type
+	if s == "" {
+		s = "system"
+	}
+	r, n := utf8.DecodeRuneInString(s)
+	s = string(unicode.ToUpper(r)) + s[n:]
+	if m.System.Domain != "" {
+		s += " in the " + m.System.Domain + " domain"
+	}
+	if m.System.Language != "" {
+		s += ", written in " + m.System.Language
+	}
+	s += "."
+	if m.Ownership.Team != "" {
+		s += " Owned by " + m.Ownership.Team
+		if m.Ownership.Slack != "" {
+			s += " (" + m.Ownership.Slack + ")"
+		}
+		s += "."
+	}
+	return s
+}
+
+// link renders a Markdown link to a map path. Globs link to their static prefix.
+func link(title, p string, opt Options) string {
+	target := strings.TrimSpace(p)
+	if !strings.Contains(target, "://") {
+		target = aimap.StaticPrefix(target)
+		if opt.BaseURL != "" {
+			target = strings.TrimSuffix(opt.BaseURL, "/") + "/" + target
+		}
+	}
+	return fmt.Sprintf("[%s](%s)", title, target)
+}
+
+// flag marks a path that falls under a critical area.
+func flag(m *aimap.Map, p string) string {
+	for _, c := range m.Boundaries.Critical {
+		if aimap.MatchPath(c, aimap.StaticPrefix(p)) {
+			return " (critical)"
+		}
+	}
+	return ""
+}
+
+func section(b *bytes.Buffer, title string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "\n## %s\n\n", title)
+	for _, it := range items {
+		fmt.Fprintf(b, "- %s\n", it)
+	}
+}
@@ -0,0 +1,62 @@
+package llmstxt
+
+import (
+	"testing"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+func TestGenerate(t *testing.T) {
+	m, err := aimap.Parse([]byte(`version: 1
+system:
+  name: orders
+  type: service
+  domain: commerce
+  language: go
+boundaries:
+  entrypoints:
+    http: [api]
+    queue: ["billing/consumers/**"]
+  models: [model]
+  critical: [billing]
+ownership:
+  team: payments
+  docs:
+    runbook: docs/runbook.md
+    adr: https://adr.example.com/orders
+runtime:
+  config_paths: [config]
+extensions:
+  ai-flow:
+    ignore: [gen]
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "# orders\n\n" +
+		"> Service in the commerce domain, written in go. Owned by payments.\n\n" +
+		"Generated from .ai-map.yaml by `ai-map export --format llms-txt`; edit the map, not this file.\n\n" +
+		"Critical areas (changes need review by payments):\n\n- `billing`\n\n" +
+		"Do not read or edit:\n\n- `gen`\n\n" +
+		"## Entrypoints\n\n" +
+		"- [api](https://git.example.com/orders/api): http entrypoint\n" +
+		"- [billing/consumers/**](https://git.example.com/orders/billing/consumers): queue entrypoint (critical)\n\n" +
+		"## Models\n\n- [model](https://git.example.com/orders/model)\n\n" +
+		"## Docs\n\n" +
+		"- [Runbook](https://git.example.com/orders/docs/runbook.md)\n" +
+		"- [Architecture decision records](https://adr.example.com/orders)\n\n" +
+		"## Optional\n\n- [config](https://git.example.com/orders/config): configuration\n"
+	if got := string(Generate(m, Options{BaseURL: "https://git.example.com/orders/"})); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestSummaryCapitalizesFirstRune(t *testing.T) {
+	m, err := aimap.Parse([]byte("version: 1\nsystem:\n  name: caisse\n  type: équipe\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := summary(m), "Équipe."; got != want {
+		t.Fatalf("summary = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,86 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// maxMessageBytes bounds a single JSON-RPC message body.
+const maxMessageBytes = 64 << 20
+
+// conn frames JSON-RPC messages with LSP's Content-Length headers.
+type conn struct {
+	r  *textproto.Reader
+	mu sync.Mutex
+	w  io.Writer
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{r: textproto.NewReader(bufio.NewReader(r)), w: w}
+}
+
+// read returns the next message; io.EOF when the client closed the stream.
+func (c *conn) read() (*message, error) {
+	h, err := c.r.ReadMIMEHeader()
+	if err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, err
+	}
+	n, err := strconv.Atoi(h.Get("Content-Length"))
+	if err != nil || n < 0 || n > maxMessageBytes {
+		return nil, fmt.Errorf("invalid Content-Length %q", h.Get("Content-Length"))
+	}
+	body := make([]byte, n)
+	if _, err := io.ReadFull(c.r.R, body); err != nil {
+		return nil, err
+	}
+	var m message
+	if err := json.Unmarshal(body, &m); err != nil {
+		return &message{}, fmt.Errorf("invalid message: %w", err)
+	}
+	return &m, nil
+}
+
+func (c *conn) write(m *message) error {
+	m.JSONRPC = "2.0"
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(b)); err != nil {
+		return err
+	}
+	_, err = c.w.Write(b)
+	return err
+}
+
+func (c *conn) reply(id *json.RawMessage, result any) error {
+	if result == nil {
+		// JSON-RPC requires a result member; null is valid for LSP requests.
+		raw := json.RawMessage("null")
+		return c.write(&message{ID: id, Result: &raw})
+	}
+	return c.write(&message{ID: id, Result: result})
+}
+
+func (c *conn) replyError(id *json.RawMessage, code int, msg string) error {
+	return c.write(&message{ID: id, Error: &responseError{Code: code, Message: msg}})
+}
+
+func (c *conn) notify(method string, params any) error {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.write(&message{Method: method, Params: b})
+}
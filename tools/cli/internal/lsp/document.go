@@ -0,0 +1,228 @@
+package lsp
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"gopkg.in/yaml.v3"
+)
+
+// document is an open text buffer and, when it parses, its YAML tree.
+type document struct {
+	uri   string
+	text  string
+	lines []string
+	// root is the top-level node of the first YAML document (nil when unparseable).
+	root *yaml.Node
+}
+
+func newDocument(uri, text string) *document {
+	d := &document{uri: uri, text: text, lines: strings.Split(text, "\n")}
+	var n yaml.Node
+	if err := yaml.Unmarshal([]byte(text), &n); err == nil && n.Kind == yaml.DocumentNode && len(n.Content) > 0 {
+		d.root = n.Content[0]
+	}
+	return d
+}
+
+func (d *document) line(i int) string {
+	if i < 0 || i >= len(d.lines) {
+		return ""
+	}
+	return strings.TrimSuffix(d.lines[i], "\r")
+}
+
+// utf16Col converts a 0-based rune column on line i to LSP's UTF-16 offset.
+func (d *document) utf16Col(i, runeCol int) int {
+	n := 0
+	for j, r := range []rune(d.line(i)) {
+		if j >= runeCol {
+			break
+		}
+		n += len(utf16.Encode([]rune{r}))
+	}
+	return n
+}
+
+// byteCol converts an LSP UTF-16 offset on line i to a byte offset.
+func (d *document) byteCol(i, utf16Col int) int {
+	l := d.line(i)
+	n := 0
+	for b, r := range l {
+		if n >= utf16Col {
+			return b
+		}
+		n += len(utf16.Encode([]rune{r}))
+	}
+	return len(l)
+}
+
+// nodeRange spans a scalar's token (including quotes) on its first line.
+func (d *document) nodeRange(n *yaml.Node) Range {
+	line, col := n.Line-1, n.Column-1
+	width := utf8.RuneCountInString(n.Value)
+	if n.Style&(yaml.DoubleQuotedStyle|yaml.SingleQuotedStyle) != 0 {
+		width += 2
+	}
+	if n.Kind != yaml.ScalarNode || strings.Contains(n.Value, "\n") {
+		width = utf8.RuneCountInString(d.line(line)) - col
+	}
+	return Range{
+		Start: Position{Line: line, Character: d.utf16Col(line, col)},
+		End:   Position{Line: line, Character: d.utf16Col(line, col+width)},
+	}
+}
+
+// find returns the key and value nodes at path; segments are mapping keys or
+// sequence indices. key is nil for sequence items and the root.
+func (d *document) find(path []string) (key, val *yaml.Node) {
+	val = d.root
+	for _, seg := range path {
+		if val == nil {
+			return nil, nil
+		}
+		switch val.Kind {
+		case yaml.MappingNode:
+			var next *yaml.Node
+			for i := 0; i+1 < len(val.Content); i += 2 {
+				if val.Content[i].Value == seg {
+					key, next = val.Content[i], val.Content[i+1]
+					break
+				}
+			}
+			val = next
+		case yaml.SequenceNode:
+			i, err := strconv.Atoi(seg)
+			if err != nil || i < 0 || i >= len(val.Content) {
+				return nil, nil
+			}
+			key, val = nil, val.Content[i]
+		default:
+			return nil, nil
+		}
+	}
+	return key, val
+}
+
+// rangeFor is where a diagnostic about path belongs: the value for scalars,
+// otherwise the key, falling back to the nearest existing ancestor.
+func (d *document) rangeFor(path []string) Range {
+	for len(path) > 0 {
+		key, val := d.find(path)
+		if val != nil && val.Kind == yaml.ScalarNode {
+			return d.nodeRange(val)
+		}
+		if key != nil {
+			return d.nodeRange(key)
+		}
+		if val != nil {
+			return d.nodeRange(val)
+		}
+		path = path[:len(path)-1]
+	}
+	return Range{End: Position{Line: 0, Character: d.utf16Col(0, utf8.RuneCountInString(d.line(0)))}}
+}
+
+// at returns the key or scalar value under pos, with its path. isKey reports
+// whether n is a mapping key; for values, path ends with the owning key or index.
+func (d *document) at(pos Position) (path []string, n *yaml.Node, isKey bool) {
+	if d.root == nil {
+		return nil, nil, false
+	}
+	contains := func(n *yaml.Node) bool {
+		r := d.nodeRange(n)
+		return n.Kind == yaml.ScalarNode && pos.Line == r.Start.Line && pos.Character >= r.Start.Character && pos.Character <= r.End.Character
+	}
+	var walk func(n *yaml.Node, path []string) ([]string, *yaml.Node, bool)
+	walk = func(n *yaml.Node, path []string) ([]string, *yaml.Node, bool) {
+		switch n.Kind {
+		case yaml.MappingNode:
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				k, v := n.Content[i], n.Content[i+1]
+				p := append(append([]string(nil), path...), k.Value)
+				if contains(k) {
+					return p, k, true
+				}
+				if contains(v) {
+					return p, v, false
+				}
+				if r, found, key := walk(v, p); found != nil {
+					return r, found, key
+				}
+			}
+		case yaml.SequenceNode:
+			for i, it := range n.Content {
+				p := append(append([]string(nil), path...), strconv.Itoa(i))
+				if contains(it) {
+					return p, it, false
+				}
+				if r, found, key := walk(it, p); found != nil {
+					return r, found, key
+				}
+			}
+		}
+		return nil, nil, false
+	}
+	return walk(d.root, nil)
+}
+
+// completionContext describes the cursor for completion, derived from the raw
+// text so it works while the buffer is mid-edit and not valid YAML.
+type completionContext struct {
+	// parent is the path of the enclosing mapping.
+	parent []string
+	// key is set when completing a value (after "key:") and empty for keys.
+	key string
+	// listItem is set when completing a "- " item of the list at parent.
+	listItem bool
+	// partial is the text typed so far for the token being completed.
+	partial string
+}
+
+func (d *document) completionAt(pos Position) completionContext {
+	line := d.line(pos.Line)
+	prefix := line[:d.byteCol(pos.Line, pos.Character)]
+	indent := len(prefix) - len(strings.TrimLeft(prefix, " "))
+	body := strings.TrimLeft(prefix, " ")
+
+	var ctx completionContext
+	if strings.HasPrefix(body, "- ") || body == "-" {
+		ctx.listItem = true
+		ctx.partial = strings.TrimSpace(strings.TrimPrefix(body, "-"))
+	} else if k, v, ok := strings.Cut(body, ":"); ok {
+		ctx.key = strings.TrimSpace(k)
+		ctx.partial = strings.TrimSpace(v)
+	} else {
+		ctx.partial = strings.TrimSpace(body)
+	}
+	ctx.partial = strings.Trim(ctx.partial, `"'`)
+
+	// Walk up to collect the enclosing keys: each less-indented "key:" line.
+	limit := indent
+	if ctx.listItem {
+		// A list item may sit at the same indentation as its key.
+		limit = indent + 1
+	}
+	var parents []string
+	for i := pos.Line - 1; i >= 0 && limit > 0; i-- {
+		l := d.line(i)
+		t := strings.TrimLeft(l, " ")
+		if t == "" || strings.HasPrefix(t, "#") {
+			continue
+		}
+		ind := len(l) - len(t)
+		if ind >= limit || strings.HasPrefix(t, "-") {
+			continue
+		}
+		k, _, ok := strings.Cut(t, ":")
+		if !ok {
+			continue
+		}
+		parents = append([]string{strings.TrimSpace(k)}, parents...)
+		limit = ind
+	}
+	ctx.parent = parents
+	return ctx
+}
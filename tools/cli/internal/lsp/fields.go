@@ -0,0 +1,149 @@
+package lsp
+
+import (
+	"sort"
+	"strings"
+)
+
+// field describes one key of the v1 spec (spec sections 2 and 3).
+type field struct {
+	doc string
+	// enum lists the values spec section 2 names for the field.
+	enum []string
+	// paths marks fields (or list items) holding repository paths.
+	paths bool
+	// object marks mappings whose keys are checked against the catalog.
+	object bool
+}
+
+// fields is keyed by dotted path; "*" stands for any key (entrypoint protocols).
+var fields = map[string]field{
+	"version":    {doc: "**version** (required): spec version. Allows future expansion with backward compatibility.", enum: []string{"1"}},
+	"system":     {doc: "**system** (required): describes the identity of the system.", object: true},
+	"boundaries": {doc: "**boundaries**: locations AI should treat as meaningful architectural boundaries.", object: true},
+	"dependencies": {
+		doc:    "**dependencies**: internal and external service dependencies. Agents use these to infer cross-system impact and execution context.",
+		object: true,
+	},
+	"ownership":  {doc: "**ownership**: links system components to human owners and documentation.", object: true},
+	"runtime":    {doc: "**runtime**: execution, configuration, and deployment metadata.", object: true},
+	"extensions": {doc: "**extensions**: tool-specific blocks keyed by extension name (spec section 5), e.g. `ai-flow`."},
+
+	"system.name":     {doc: "**system.name** (required): canonical system identifier."},
+	"system.type":     {doc: "**system.type**: informs agents how to interpret directory layout.", enum: []string{"service", "webapp", "library", "infra", "monorepo"}},
+	"system.domain":   {doc: "**system.domain**: business or functional domain."},
+	"system.language": {doc: "**system.language**: primary implementation language."},
+
+	"boundaries.entrypoints":   {doc: "**boundaries.entrypoints**: paths initiating system behavior (e.g. API resolvers, controllers, CLI handlers), grouped by protocol."},
+	"boundaries.entrypoints.*": {doc: "Entrypoint paths for this protocol.", paths: true},
+	"boundaries.models":        {doc: "**boundaries.models**: paths defining domain models, schemas, or entity definitions.", paths: true},
+	"boundaries.critical":      {doc: "**boundaries.critical**: paths containing essential or high-risk logic that agents should treat with extra caution.", paths: true},
+
+	"dependencies.internal": {doc: "**dependencies.internal**: repositories or systems of the same organisation this one depends on."},
+	"dependencies.external": {doc: "**dependencies.external**: external services this system depends on."},
+
+	"ownership.team":         {doc: "**ownership.team**: owning team."},
+	"ownership.slack":        {doc: "**ownership.slack**: team channel."},
+	"ownership.docs":         {doc: "**ownership.docs**: documentation locations.", object: true},
+	"ownership.docs.adr":     {doc: "**ownership.docs.adr**: architecture decision records.", paths: true},
+	"ownership.docs.runbook": {doc: "**ownership.docs.runbook**: operational runbook.", paths: true},
+	"ownership.docs.openapi": {doc: "**ownership.docs.openapi**: the OpenAPI / Swagger description `ai-map import --from openapi` derived `boundaries.entrypoints.http` from.", paths: true},
+
+	"runtime.environment":  {doc: "**runtime.environment**: where the system executes.", enum: []string{"lambda", "container", "node", "browser", "worker", "cli"}},
+	"runtime.deploys_via":  {doc: "**runtime.deploys_via**: deployment mechanism.", enum: []string{"github-actions", "cdk", "terraform", "manual", "other"}},
+	"runtime.config_paths": {doc: "**runtime.config_paths**: configuration files and directories.", paths: true},
+}
+
+// topLevel is the object holding the document's root keys.
+const topLevel = ""
+
+// lookupField resolves a concrete path (e.g. boundaries.entrypoints.http) to its
+// catalog entry.
+func lookupField(path []string) (field, bool) {
+	if f, ok := fields[strings.Join(path, ".")]; ok {
+		return f, true
+	}
+	if len(path) == 3 && path[0] == "boundaries" && path[1] == "entrypoints" {
+		f, ok := fields["boundaries.entrypoints.*"]
+		return f, ok
+	}
+	return field{}, false
+}
+
+// childKeys lists the catalog keys allowed directly under the object at parent.
+// ok is false when the object's keys are free-form (or unknown).
+func childKeys(parent []string) (keys []string, ok bool) {
+	p := strings.Join(parent, ".")
+	if p != topLevel {
+		f, found := fields[p]
+		if !found || !f.object {
+			return nil, false
+		}
+	}
+	for k := range fields {
+		if strings.HasSuffix(k, ".*") {
+			continue
+		}
+		var rest string
+		if p == topLevel {
+			rest = k
+		} else if strings.HasPrefix(k, p+".") {
+			rest = strings.TrimPrefix(k, p+".")
+		} else {
+			continue
+		}
+		if !strings.Contains(rest, ".") {
+			keys = append(keys, rest)
+		}
+	}
+	sort.Strings(keys)
+	return keys, true
+}
+
+// closest returns the candidate nearest to s by edit distance, if it is close
+// enough to be a plausible typo.
+func closest(s string, candidates []string) (string, bool) {
+	best, bestD := "", -1
+	for _, c := range candidates {
+		if d := editDistance(strings.ToLower(s), c); bestD < 0 || d < bestD {
+			best, bestD = c, d
+		}
+	}
+	limit := len(s) / 3
+	if limit < 1 {
+		limit = 1
+	}
+	if limit > 3 {
+		limit = 3
+	}
+	if bestD < 0 || bestD > limit {
+		return "", false
+	}
+	return best, true
+}
+
+// editDistance is the Damerau-Levenshtein (optimal string alignment) distance.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	d := make([][]int, len(ra)+1)
+	for i := range d {
+		d[i] = make([]int, len(rb)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			d[i][j] = min(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				d[i][j] = min(d[i][j], d[i-2][j-2]+1)
+			}
+		}
+	}
+	return d[len(ra)][len(rb)]
+}
@@ -0,0 +1,443 @@
+// Package lsp implements a Language Server Protocol server for AI-Map files over
+// stdio: schema and lint diagnostics with ranges, completion of keys, enum values
+// and boundary paths, hover docs from the spec, and quick-fixes for typos.
+//
+// Requests are handled one at a time in arrival order; every response is
+// computed from the in-memory buffers, so the server never writes to disk.
+package lsp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/discover"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/lint"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/validate"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/version"
+	"gopkg.in/yaml.v3"
+)
+
+// Options configures the checks behind diagnostics.
+type Options struct {
+	Validator *validate.Validator
+	Lint      lint.Options
+}
+
+type server struct {
+	opt         Options
+	conn        *conn
+	docs        map[string]*document
+	initialized bool
+	shutdown    bool
+}
+
+// Serve speaks LSP on r/w until the client sends exit or closes the stream.
+func Serve(r io.Reader, w io.Writer, opt Options) error {
+	if opt.Validator == nil {
+		return errors.New("lsp: validator is required")
+	}
+	s := &server{opt: opt, conn: newConn(r, w), docs: map[string]*document{}}
+	for {
+		m, err := s.conn.read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			if m == nil {
+				return err
+			}
+			// A malformed body: report it and keep the session alive.
+			_ = s.conn.replyError(nil, codeParseError, err.Error())
+			continue
+		}
+		if m.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("lsp: exit without shutdown")
+			}
+			return nil
+		}
+		if err := s.handle(m); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *server) handle(m *message) error {
+	isRequest := m.ID != nil
+	if !s.initialized && m.Method != "initialize" {
+		if isRequest {
+			return s.conn.replyError(m.ID, codeNotInitialized, "server not initialized")
+		}
+		return nil
+	}
+
+	switch m.Method {
+	case "initialize":
+		s.initialized = true
+		return s.conn.reply(m.ID, map[string]any{
+			"capabilities": map[string]any{
+				"textDocumentSync":   1, // full
+				"hoverProvider":      true,
+				"completionProvider": map[string]any{"triggerCharacters": []string{":", " ", "/", "-"}},
+				"codeActionProvider": map[string]any{"codeActionKinds": []string{"quickfix"}},
+			},
+			"serverInfo": map[string]any{"name": "ai-map", "version": version.Version},
+		})
+	case "initialized", "$/cancelRequest", "$/setTrace", "workspace/didChangeConfiguration":
+		return nil
+	case "shutdown":
+		s.shutdown = true
+		return s.conn.reply(m.ID, nil)
+
+	case "textDocument/didOpen":
+		var p DidOpenParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil
+		}
+		return s.update(p.TextDocument.URI, p.TextDocument.Text)
+	case "textDocument/didChange":
+		var p DidChangeParams
+		if err := json.Unmarshal(m.Params, &p); err != nil || len(p.ContentChanges) == 0 {
+			return nil
+		}
+		// Full sync: the last change holds the whole buffer.
+		return s.update(p.TextDocument.URI, p.ContentChanges[len(p.ContentChanges)-1].Text)
+	case "textDocument/didClose":
+		var p DidCloseParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return nil
+		}
+		delete(s.docs, p.TextDocument.URI)
+		return s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: p.TextDocument.URI, Diagnostics: []Diagnostic{}})
+
+	case "textDocument/completion":
+		var p TextDocumentPositionParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return s.conn.replyError(m.ID, codeInvalidParams, err.Error())
+		}
+		d := s.docs[p.TextDocument.URI]
+		if d == nil {
+			return s.conn.reply(m.ID, nil)
+		}
+		return s.conn.reply(m.ID, CompletionList{Items: complete(d, p.Position)})
+	case "textDocument/hover":
+		var p TextDocumentPositionParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return s.conn.replyError(m.ID, codeInvalidParams, err.Error())
+		}
+		d := s.docs[p.TextDocument.URI]
+		if d == nil {
+			return s.conn.reply(m.ID, nil)
+		}
+		if h := hover(d, p.Position); h != nil {
+			return s.conn.reply(m.ID, h)
+		}
+		return s.conn.reply(m.ID, nil)
+	case "textDocument/codeAction":
+		var p CodeActionParams
+		if err := json.Unmarshal(m.Params, &p); err != nil {
+			return s.conn.replyError(m.ID, codeInvalidParams, err.Error())
+		}
+		d := s.docs[p.TextDocument.URI]
+		if d == nil {
+			return s.conn.reply(m.ID, []CodeAction{})
+		}
+		return s.conn.reply(m.ID, quickFixes(d, p.Context.Diagnostics))
+	}
+
+	if isRequest {
+		return s.conn.replyError(m.ID, codeMethodNotFound, "method not supported: "+m.Method)
+	}
+	return nil
+}
+
+func (s *server) update(uri, text string) error {
+	d := newDocument(uri, text)
+	s.docs[uri] = d
+	return s.conn.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: uri, Diagnostics: s.diagnose(d)})
+}
+
+var yamlErrLine = regexp.MustCompile(`line (\d+):`)
+
+// diagnose runs the same checks as validate and lint, plus unknown-key and
+// enum-value checks against the spec's field catalog.
+func (s *server) diagnose(d *document) []Diagnostic {
+	out := []Diagnostic{}
+	b := []byte(d.text)
+
+	res := s.opt.Validator.ValidateBytes(b)
+	if d.root == nil {
+		// Parse errors carry a line number in the message.
+		for _, e := range res.Errors {
+			r := Range{}
+			if m := yamlErrLine.FindStringSubmatch(e); m != nil {
+				n, _ := strconv.Atoi(m[1])
+				r = Range{Start: Position{Line: n - 1}, End: Position{Line: n - 1, Character: d.utf16Col(n-1, len([]rune(d.line(n-1))))}}
+			}
+			out = append(out, Diagnostic{Range: r, Severity: SeverityError, Source: "ai-map", Message: e})
+		}
+		return out
+	}
+
+	schemaErrs := res.Errors
+	if len(schemaErrs) > 1 {
+		// Drop the top-level "doesn't validate with <schema>" wrapper.
+		var kept []string
+		for _, e := range schemaErrs {
+			if !strings.HasPrefix(e, ": doesn't validate with") {
+				kept = append(kept, e)
+			}
+		}
+		schemaErrs = kept
+	}
+	for _, e := range schemaErrs {
+		loc, msg := splitPointer(e)
+		out = append(out, Diagnostic{Range: d.rangeFor(loc), Severity: SeverityError, Source: "ai-map validate", Message: msg})
+	}
+	for _, w := range res.Warnings {
+		loc, msg := splitPointer(w)
+		out = append(out, Diagnostic{Range: d.rangeFor(loc), Severity: SeverityWarning, Source: "ai-map validate", Message: msg})
+	}
+
+	lres := lint.LintYAMLBytes(b)
+	if p := docPath(d.uri); p != "" {
+		lres.Issues = append(lres.Issues, lint.LintFile(p, b, s.opt.Lint).Issues...)
+	}
+	for _, is := range s.opt.Lint.Apply(lres).Issues {
+		var path []string
+		if is.Path != "" {
+			path = strings.Split(is.Path, ".")
+		}
+		sev := SeverityWarning
+		if is.Severity == lint.SeverityError {
+			sev = SeverityError
+		}
+		out = append(out, Diagnostic{Range: d.rangeFor(path), Severity: sev, Code: is.Rule, Source: "ai-map lint", Message: is.Message})
+	}
+
+	out = append(out, catalogDiagnostics(d)...)
+	sort.SliceStable(out, func(i, j int) bool {
+		a, b := out[i].Range.Start, out[j].Range.Start
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Character < b.Character
+	})
+	return out
+}
+
+// splitPointer splits "/a/b: message" into path segments and message.
+func splitPointer(e string) ([]string, string) {
+	if !strings.HasPrefix(e, "/") {
+		return nil, e
+	}
+	ptr, msg, ok := strings.Cut(e, ": ")
+	if !ok {
+		return nil, e
+	}
+	var segs []string
+	for _, s := range strings.Split(strings.TrimPrefix(ptr, "/"), "/") {
+		segs = append(segs, strings.NewReplacer("~1", "/", "~0", "~").Replace(s))
+	}
+	return segs, msg
+}
+
+// lintCovered are enum fields lint already reports, so they are not repeated.
+var lintCovered = map[string]bool{"version": true, "system.type": true}
+
+func catalogDiagnostics(d *document) []Diagnostic {
+	var out []Diagnostic
+	var walk func(n *yaml.Node, path []string)
+	walk = func(n *yaml.Node, path []string) {
+		if n.Kind != yaml.MappingNode {
+			return
+		}
+		keys, checked := childKeys(path)
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			p := append(append([]string(nil), path...), k.Value)
+			if checked && !contains(keys, k.Value) {
+				msg := fmt.Sprintf("unknown key %q", k.Value)
+				if s, ok := closest(k.Value, keys); ok {
+					msg += fmt.Sprintf(" (did you mean %q?)", s)
+				}
+				out = append(out, Diagnostic{Range: d.nodeRange(k), Severity: SeverityWarning, Code: "unknown-key", Source: "ai-map", Message: msg})
+				continue
+			}
+			f, _ := lookupField(p)
+			if len(f.enum) > 0 && !lintCovered[strings.Join(p, ".")] && v.Kind == yaml.ScalarNode && !contains(f.enum, v.Value) {
+				msg := fmt.Sprintf("unknown value %q (expected one of %s)", v.Value, strings.Join(f.enum, "|"))
+				out = append(out, Diagnostic{Range: d.nodeRange(v), Severity: SeverityWarning, Code: "unknown-value", Source: "ai-map", Message: msg})
+			}
+			walk(v, p)
+		}
+	}
+	walk(d.root, nil)
+	return out
+}
+
+func complete(d *document, pos Position) []CompletionItem {
+	ctx := d.completionAt(pos)
+	items := []CompletionItem{}
+
+	if !ctx.listItem && ctx.key == "" {
+		keys, _ := childKeys(ctx.parent)
+		for _, k := range keys {
+			if !strings.HasPrefix(k, ctx.partial) {
+				continue
+			}
+			f, _ := lookupField(append(append([]string(nil), ctx.parent...), k))
+			items = append(items, CompletionItem{Label: k, Kind: KindField, Detail: firstSentence(f.doc), InsertText: k + ": "})
+		}
+		return items
+	}
+
+	path := ctx.parent
+	if ctx.key != "" {
+		path = append(append([]string(nil), path...), ctx.key)
+	}
+	f, ok := lookupField(path)
+	if !ok {
+		return items
+	}
+	for _, v := range f.enum {
+		if strings.HasPrefix(v, ctx.partial) {
+			items = append(items, CompletionItem{Label: v, Kind: KindValue})
+		}
+	}
+	if f.paths {
+		items = append(items, completePaths(d.uri, ctx.partial)...)
+	}
+	return items
+}
+
+// completePaths lists filesystem entries matching partial, relative to the
+// directory of the document.
+func completePaths(uri, partial string) []CompletionItem {
+	base := docDir(uri)
+	if base == "" {
+		return nil
+	}
+	dirPart, namePart := "", partial
+	if i := strings.LastIndex(partial, "/"); i >= 0 {
+		dirPart, namePart = partial[:i+1], partial[i+1:]
+	}
+	ents, err := os.ReadDir(filepath.Join(base, filepath.FromSlash(dirPart)))
+	if err != nil {
+		return nil
+	}
+	var items []CompletionItem
+	for _, e := range ents {
+		name := e.Name()
+		if !strings.HasPrefix(name, namePart) || (e.IsDir() && discover.SkipDirs[name]) {
+			continue
+		}
+		it := CompletionItem{Label: dirPart + name, Kind: KindFile}
+		if e.IsDir() {
+			it.Kind = KindFolder
+		}
+		items = append(items, it)
+	}
+	return items
+}
+
+func docDir(uri string) string {
+	p := docPath(uri)
+	if p == "" {
+		return ""
+	}
+	return filepath.Dir(p)
+}
+
+// docPath returns the file a file:// URI names, or "" for other schemes.
+func docPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return ""
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+func hover(d *document, pos Position) *Hover {
+	path, n, isKey := d.at(pos)
+	if n == nil {
+		return nil
+	}
+	if !isKey && len(path) > 0 {
+		if _, err := strconv.Atoi(path[len(path)-1]); err == nil {
+			path = path[:len(path)-1]
+		}
+	}
+	f, ok := lookupField(path)
+	if !ok || f.doc == "" {
+		return nil
+	}
+	text := f.doc
+	if len(f.enum) > 0 {
+		text += "\n\nAllowed values: `" + strings.Join(f.enum, "` | `") + "`"
+	}
+	r := d.nodeRange(n)
+	return &Hover{Contents: MarkupContent{Kind: "markdown", Value: text}, Range: &r}
+}
+
+// quickFixes offers the closest known key or enum value for each diagnostic
+// that sits on an unknown key or value.
+func quickFixes(d *document, diags []Diagnostic) []CodeAction {
+	out := []CodeAction{}
+	for _, diag := range diags {
+		path, n, isKey := d.at(diag.Range.Start)
+		if n == nil {
+			continue
+		}
+		var candidates []string
+		if isKey {
+			candidates, _ = childKeys(path[:len(path)-1])
+		} else if f, ok := lookupField(path); ok {
+			candidates = f.enum
+		}
+		if contains(candidates, n.Value) {
+			continue
+		}
+		fix, ok := closest(n.Value, candidates)
+		if !ok {
+			continue
+		}
+		r := d.nodeRange(n)
+		out = append(out, CodeAction{
+			Title:       fmt.Sprintf("Change to %q", fix),
+			Kind:        "quickfix",
+			Diagnostics: []Diagnostic{diag},
+			IsPreferred: true,
+			Edit:        WorkspaceEdit{Changes: map[string][]TextEdit{d.uri: {{Range: r, NewText: fix}}}},
+		})
+	}
+	return out
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func firstSentence(doc string) string {
+	if _, rest, ok := strings.Cut(doc, ": "); ok {
+		doc = rest
+	}
+	if i := strings.Index(doc, ". "); i >= 0 {
+		return doc[:i+1]
+	}
+	return doc
+}
@@ -0,0 +1,239 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/validate"
+)
+
+// client drives a server over in-memory pipes.
+type client struct {
+	t    *testing.T
+	w    io.Writer
+	r    *textproto.Reader
+	next int
+	done chan error
+}
+
+func startServer(t *testing.T) *client {
+	t.Helper()
+	v, err := validate.New(validate.Options{MaxBytes: 1 << 20})
+	if err != nil {
+		t.Fatal(err)
+	}
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &client{t: t, w: inW, r: textproto.NewReader(bufio.NewReader(outR)), done: make(chan error, 1)}
+	go func() {
+		err := Serve(inR, outW, Options{Validator: v})
+		outW.Close()
+		c.done <- err
+	}()
+	t.Cleanup(func() { inW.Close() })
+	return c
+}
+
+func (c *client) send(method string, id *int, params any) {
+	c.t.Helper()
+	m := map[string]any{"jsonrpc": "2.0", "method": method, "params": params}
+	if id != nil {
+		m["id"] = *id
+	}
+	b, _ := json.Marshal(m)
+	if _, err := fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(b), b); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+func (c *client) recv() map[string]json.RawMessage {
+	c.t.Helper()
+	h, err := c.r.ReadMIMEHeader()
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	n, _ := strconv.Atoi(h.Get("Content-Length"))
+	body := make([]byte, n)
+	if _, err := io.ReadFull(c.r.R, body); err != nil {
+		c.t.Fatal(err)
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(body, &m); err != nil {
+		c.t.Fatal(err)
+	}
+	return m
+}
+
+// call sends a request and decodes its result into out.
+func (c *client) call(method string, params, out any) {
+	c.t.Helper()
+	c.next++
+	id := c.next
+	c.send(method, &id, params)
+	m := c.recv()
+	if e, ok := m["error"]; ok {
+		c.t.Fatalf("%s: error %s", method, e)
+	}
+	if out != nil {
+		if err := json.Unmarshal(m["result"], out); err != nil {
+			c.t.Fatalf("%s: %v", method, err)
+		}
+	}
+}
+
+func (c *client) diagnostics() PublishDiagnosticsParams {
+	c.t.Helper()
+	m := c.recv()
+	var p PublishDiagnosticsParams
+	if string(m["method"]) != `"textDocument/publishDiagnostics"` {
+		c.t.Fatalf("expected diagnostics, got %v", m)
+	}
+	if err := json.Unmarshal(m["params"], &p); err != nil {
+		c.t.Fatal(err)
+	}
+	return p
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	for _, p := range []string{"src/core", "src/api", "docs"} {
+		if err := os.MkdirAll(filepath.Join(dir, p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	uri := "file://" + filepath.ToSlash(filepath.Join(dir, ".ai-map.yaml"))
+	text := strings.Join([]string{
+		"version: 1",
+		"system:",
+		"  name: edge assets",
+		"  tpye: service",
+		"runtime:",
+		"  environment: lamda",
+		"boundaries:",
+		"  critical:",
+		"    - src/c",
+		"",
+	}, "\n")
+
+	c := startServer(t)
+	var init struct {
+		Capabilities map[string]any `json:"capabilities"`
+	}
+	c.call("initialize", map[string]any{"processId": nil, "rootUri": nil, "capabilities": map[string]any{}}, &init)
+	if init.Capabilities["hoverProvider"] != true {
+		t.Fatalf("capabilities = %v", init.Capabilities)
+	}
+	c.send("initialized", nil, map[string]any{})
+
+	c.send("textDocument/didOpen", nil, map[string]any{"textDocument": map[string]any{"uri": uri, "languageId": "yaml", "version": 1, "text": text}})
+	diags := c.diagnostics().Diagnostics
+	find := func(code string) Diagnostic {
+		for _, d := range diags {
+			if d.Code == code {
+				return d
+			}
+		}
+		t.Fatalf("no %q diagnostic in %+v", code, diags)
+		return Diagnostic{}
+	}
+	if d := find("unknown-key"); d.Range.Start != (Position{Line: 3, Character: 2}) || d.Range.End != (Position{Line: 3, Character: 6}) {
+		t.Fatalf("unknown-key range = %+v", d.Range)
+	}
+	if d := find("unknown-value"); d.Range.Start != (Position{Line: 5, Character: 15}) {
+		t.Fatalf("unknown-value range = %+v", d.Range)
+	}
+	if d := find("system-name-whitespace"); d.Range.Start.Line != 2 || d.Severity != SeverityWarning {
+		t.Fatalf("lint diagnostic = %+v", d)
+	}
+
+	// Quick-fixes for the key and enum typos.
+	for _, tc := range []struct{ code, want string }{{"unknown-key", "type"}, {"unknown-value", "lambda"}} {
+		var actions []CodeAction
+		d := find(tc.code)
+		c.call("textDocument/codeAction", map[string]any{
+			"textDocument": map[string]any{"uri": uri},
+			"range":        d.Range,
+			"context":      map[string]any{"diagnostics": []Diagnostic{d}},
+		}, &actions)
+		if len(actions) != 1 || actions[0].Edit.Changes[uri][0].NewText != tc.want || actions[0].Edit.Changes[uri][0].Range != d.Range {
+			t.Fatalf("%s quick-fix = %+v", tc.code, actions)
+		}
+	}
+
+	labels := func(pos Position) []string {
+		var list CompletionList
+		c.call("textDocument/completion", map[string]any{"textDocument": map[string]any{"uri": uri}, "position": pos}, &list)
+		var out []string
+		for _, it := range list.Items {
+			out = append(out, it.Label)
+		}
+		return out
+	}
+	if got := strings.Join(labels(Position{Line: 9}), ","); got != "boundaries,dependencies,extensions,ownership,runtime,system,version" {
+		t.Fatalf("top-level keys = %s", got)
+	}
+	if got := strings.Join(labels(Position{Line: 5, Character: 15}), ","); got != "lambda,container,node,browser,worker,cli" {
+		t.Fatalf("environment values = %s", got)
+	}
+	if got := strings.Join(labels(Position{Line: 8, Character: 11}), ","); got != "src/core" {
+		t.Fatalf("path completion = %s", got)
+	}
+
+	var h Hover
+	c.call("textDocument/hover", map[string]any{"textDocument": map[string]any{"uri": uri}, "position": Position{Line: 7, Character: 4}}, &h)
+	if !strings.Contains(h.Contents.Value, "high-risk logic") {
+		t.Fatalf("hover = %+v", h)
+	}
+
+	// Fixing the document clears the catalog diagnostics.
+	fixed := strings.NewReplacer("tpye", "type", "lamda", "lambda", "edge assets", "edge-assets").Replace(text)
+	c.send("textDocument/didChange", nil, map[string]any{"textDocument": map[string]any{"uri": uri, "version": 2}, "contentChanges": []map[string]any{{"text": fixed}}})
+	if d := c.diagnostics().Diagnostics; len(d) != 0 {
+		t.Fatalf("diagnostics after fix = %+v", d)
+	}
+
+	c.call("shutdown", nil, nil)
+	c.send("exit", nil, nil)
+	if err := <-c.done; err != nil {
+		t.Fatalf("Serve = %v", err)
+	}
+}
+
+func TestFileRulesMatchLint(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "compose.yaml"), []byte("services:\n  db:\n    image: postgres:16\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	uri := "file://" + filepath.ToSlash(filepath.Join(dir, ".ai-map.yaml"))
+	c := startServer(t)
+	c.call("initialize", map[string]any{"processId": nil, "rootUri": nil, "capabilities": map[string]any{}}, nil)
+	c.send("textDocument/didOpen", nil, map[string]any{"textDocument": map[string]any{"uri": uri, "languageId": "yaml", "version": 1, "text": "version: 1\nsystem:\n  name: shop\n"}})
+	var codes []string
+	for _, d := range c.diagnostics().Diagnostics {
+		codes = append(codes, d.Code)
+	}
+	if strings.Join(codes, ",") != "compose-mismatch" {
+		t.Fatalf("diagnostic codes = %v, want the compose-mismatch lint reports", codes)
+	}
+}
+
+func TestCompletionContextWhileEditing(t *testing.T) {
+	d := newDocument("file:///x/.ai-map.yaml", "version: 1\nsystem:\n  ty\nboundaries:\n  models:\n  - src\n  entrypoints:\n    http:\n      - ")
+	if ctx := d.completionAt(Position{Line: 2, Character: 4}); strings.Join(ctx.parent, ".") != "system" || ctx.partial != "ty" || ctx.key != "" {
+		t.Fatalf("key context = %+v", ctx)
+	}
+	if ctx := d.completionAt(Position{Line: 5, Character: 5}); strings.Join(ctx.parent, ".") != "boundaries.models" || !ctx.listItem {
+		t.Fatalf("list context = %+v", ctx)
+	}
+	if ctx := d.completionAt(Position{Line: 8, Character: 8}); strings.Join(ctx.parent, ".") != "boundaries.entrypoints.http" || !ctx.listItem {
+		t.Fatalf("entrypoint context = %+v", ctx)
+	}
+}
@@ -0,0 +1,301 @@
+// Package migrate upgrades AI-Map documents between spec versions.
+//
+// Each spec change ships a Step that rewrites the YAML node tree in place.
+// Migrate then patches only the source lines of the scalars a step changed, so
+// comments, blank lines and indentation survive the upgrade. Steps are registered in steps.go
+// and chained by their From/To versions.
+package migrate
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/yamledit"
+	"gopkg.in/yaml.v3"
+)
+
+// Step rewrites a document from spec version From to spec version To.
+// A step with From == To is the identity step for that version: it runs when a
+// document is already at the target version and only canonicalizes it.
+type Step struct {
+	Name  string
+	From  int
+	To    int
+	Apply func(doc *yaml.Node) error
+}
+
+var registry []Step
+
+// Register adds a step to the registry. It panics on malformed or duplicate steps
+// because registration happens at init time.
+func Register(s Step) {
+	if s.Name == "" || s.Apply == nil {
+		panic("migrate: step needs a name and an Apply func")
+	}
+	if s.To < s.From {
+		panic(fmt.Sprintf("migrate: step %q downgrades %d -> %d", s.Name, s.From, s.To))
+	}
+	for _, r := range registry {
+		if r.Name == s.Name || (r.From == s.From && (r.To == r.From) == (s.To == s.From)) {
+			panic(fmt.Sprintf("migrate: step %q conflicts with %q", s.Name, r.Name))
+		}
+	}
+	registry = append(registry, s)
+	sort.Slice(registry, func(i, j int) bool {
+		if registry[i].From != registry[j].From {
+			return registry[i].From < registry[j].From
+		}
+		return registry[i].To < registry[j].To
+	})
+}
+
+// Steps returns the registered steps ordered by version.
+func Steps() []Step {
+	return append([]Step(nil), registry...)
+}
+
+// Latest returns the newest spec version any registered step produces.
+func Latest() int {
+	latest := 0
+	for _, s := range registry {
+		if s.To > latest {
+			latest = s.To
+		}
+	}
+	return latest
+}
+
+// Plan returns the steps that take a document from version from to version to.
+func Plan(from, to int) ([]Step, error) {
+	if to < from {
+		return nil, fmt.Errorf("cannot migrate from version %d down to %d", from, to)
+	}
+	if from == to {
+		for _, s := range registry {
+			if s.From == from && s.To == from {
+				return []Step{s}, nil
+			}
+		}
+		return nil, fmt.Errorf("unsupported spec version %d", from)
+	}
+	var plan []Step
+	for cur := from; cur < to; {
+		next, ok := stepFrom(cur)
+		if !ok {
+			return nil, fmt.Errorf("no migration registered from version %d", cur)
+		}
+		if next.To > to {
+			return nil, fmt.Errorf("no migration path from version %d to %d", from, to)
+		}
+		plan = append(plan, next)
+		cur = next.To
+	}
+	return plan, nil
+}
+
+func stepFrom(v int) (Step, bool) {
+	for _, s := range registry {
+		if s.From == v && s.To > v {
+			return s, true
+		}
+	}
+	return Step{}, false
+}
+
+type Result struct {
+	From    int
+	To      int
+	Steps   []string
+	Changed bool
+	// Output is the migrated document; it equals the input when Changed is false.
+	Output []byte
+}
+
+// Migrate upgrades src to version target (Latest() when target <= 0).
+func Migrate(src []byte, target int) (Result, error) {
+	if target <= 0 {
+		target = Latest()
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return Result{}, fmt.Errorf("YAML parse error: %w", err)
+	}
+	root, err := rootMapping(&doc)
+	if err != nil {
+		return Result{}, err
+	}
+	from, err := DocumentVersion(root)
+	if err != nil {
+		return Result{}, err
+	}
+	plan, err := Plan(from, target)
+	if err != nil {
+		return Result{}, err
+	}
+
+	before := snapshot(&doc)
+	res := Result{From: from, To: target}
+	for _, s := range plan {
+		if err := s.Apply(root); err != nil {
+			return Result{}, fmt.Errorf("step %s: %w", s.Name, err)
+		}
+		if err := sameShape(before, &doc); err != nil {
+			return Result{}, fmt.Errorf("step %s: %w", s.Name, err)
+		}
+		res.Steps = append(res.Steps, s.Name)
+	}
+
+	// Only rewrite the lines of changed scalars, so blank lines, indentation
+	// and comment spacing elsewhere stay exactly as written.
+	out, err := patch(src, before, &doc)
+	if err != nil {
+		return Result{}, err
+	}
+	if bytes.Equal(out, src) {
+		res.Output = src
+		return res, nil
+	}
+	res.Changed = true
+	res.Output = out
+	return res, nil
+}
+
+// DocumentVersion reads the top-level `version` of a mapping node.
+// Integral floats such as 1.0 are accepted.
+func DocumentVersion(root *yaml.Node) (int, error) {
+	v := MappingValue(root, "version")
+	if v == nil {
+		return 0, errors.New("missing required field: version")
+	}
+	if v.Kind != yaml.ScalarNode {
+		return 0, errors.New("version must be a number")
+	}
+	f, err := strconv.ParseFloat(v.Value, 64)
+	if err != nil || math.Trunc(f) != f || f < 1 {
+		return 0, fmt.Errorf("version must be a positive whole number, got %q", v.Value)
+	}
+	return int(f), nil
+}
+
+// MappingValue returns the value node for key in a mapping node, or nil.
+func MappingValue(m *yaml.Node, key string) *yaml.Node {
+	if m == nil || m.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// SetVersion rewrites the top-level version value, keeping its comments.
+func SetVersion(root *yaml.Node, v int) error {
+	n := MappingValue(root, "version")
+	if n == nil {
+		return errors.New("missing required field: version")
+	}
+	n.Kind = yaml.ScalarNode
+	n.Tag = "!!int"
+	n.Style = 0
+	n.Value = strconv.Itoa(v)
+	return nil
+}
+
+func rootMapping(doc *yaml.Node) (*yaml.Node, error) {
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, errors.New("empty document")
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, errors.New("top-level document must be a mapping/object")
+	}
+	return root, nil
+}
+
+// nodeState is what patch compares to find the scalars a step changed.
+type nodeState struct {
+	kind  yaml.Kind
+	tag   string
+	value string
+	style yaml.Style
+	items int
+}
+
+func snapshot(doc *yaml.Node) map[*yaml.Node]nodeState {
+	states := map[*yaml.Node]nodeState{}
+	walk(doc, func(n *yaml.Node) {
+		states[n] = nodeState{kind: n.Kind, tag: n.Tag, value: n.Value, style: n.Style, items: len(n.Content)}
+	})
+	return states
+}
+
+func walk(n *yaml.Node, fn func(*yaml.Node)) {
+	fn(n)
+	for _, c := range n.Content {
+		walk(c, fn)
+	}
+}
+
+// sameShape reports an error when doc gained, lost or replaced nodes since
+// before was taken. Steps may only change scalar values for now.
+func sameShape(before map[*yaml.Node]nodeState, doc *yaml.Node) error {
+	var err error
+	walk(doc, func(n *yaml.Node) {
+		was, ok := before[n]
+		switch {
+		case err != nil:
+		case !ok || was.kind != n.Kind || was.items != len(n.Content):
+			err = errors.New("changes the document's structure; only scalar values can be rewritten in place")
+		}
+	})
+	return err
+}
+
+// patch rewrites, in src, each scalar of doc whose value, tag or style differs
+// from before. Every other byte is kept.
+func patch(src []byte, before map[*yaml.Node]nodeState, doc *yaml.Node) ([]byte, error) {
+	var changed []*yaml.Node
+	walk(doc, func(n *yaml.Node) {
+		was := before[n]
+		if n.Kind == yaml.ScalarNode && (was.value != n.Value || was.tag != n.Tag || was.style != n.Style) {
+			changed = append(changed, n)
+		}
+	})
+	if len(changed) == 0 {
+		return src, nil
+	}
+	// Patch right to left so earlier columns on a shared line stay valid.
+	sort.Slice(changed, func(i, j int) bool {
+		if changed[i].Line != changed[j].Line {
+			return changed[i].Line < changed[j].Line
+		}
+		return changed[i].Column > changed[j].Column
+	})
+	lines := strings.Split(string(src), "\n")
+	for _, n := range changed {
+		was := before[n]
+		line := lines[n.Line-1]
+		start := n.Column - 1
+		end := yamledit.ScalarEnd(line, start, &yaml.Node{Value: was.value, Style: was.style})
+		if end < 0 {
+			return nil, fmt.Errorf("line %d: unsupported value layout; edit the map by hand", n.Line)
+		}
+		text, err := yaml.Marshal(&yaml.Node{Kind: yaml.ScalarNode, Tag: n.Tag, Value: n.Value, Style: n.Style})
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", n.Line, err)
+		}
+		value := strings.TrimSuffix(string(text), "\n")
+		if strings.Contains(value, "\n") {
+			return nil, fmt.Errorf("line %d: new value spans several lines; edit the map by hand", n.Line)
+		}
+		lines[n.Line-1] = line[:start] + value + line[end:]
+	}
+	return []byte(strings.Join(lines, "\n")), nil
+}
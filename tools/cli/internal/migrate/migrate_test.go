@@ -0,0 +1,66 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestStepFixtures runs every registered step against testdata/<step name>/*.in.yaml
+// and compares the result with the matching *.out.yaml. A step without fixtures fails,
+// so each spec change lands together with its migration cases.
+func TestStepFixtures(t *testing.T) {
+	for _, s := range Steps() {
+		s := s
+		t.Run(s.Name, func(t *testing.T) {
+			ins, err := filepath.Glob(filepath.Join("testdata", s.Name, "*.in.yaml"))
+			if err != nil {
+				t.Fatalf("glob: %v", err)
+			}
+			if len(ins) == 0 {
+				t.Fatalf("no fixtures under testdata/%s/", s.Name)
+			}
+			for _, in := range ins {
+				src, err := os.ReadFile(in)
+				if err != nil {
+					t.Fatalf("read %s: %v", in, err)
+				}
+				want, err := os.ReadFile(strings.TrimSuffix(in, ".in.yaml") + ".out.yaml")
+				if err != nil {
+					t.Fatalf("read expected output for %s: %v", in, err)
+				}
+				res, err := Migrate(src, s.To)
+				if err != nil {
+					t.Fatalf("%s: Migrate: %v", in, err)
+				}
+				if string(res.Output) != string(want) {
+					t.Errorf("%s: output mismatch\n--- got ---\n%s\n--- want ---\n%s", in, res.Output, want)
+				}
+				if res.Changed == (string(src) == string(want)) {
+					t.Errorf("%s: Changed=%v does not match fixture", in, res.Changed)
+				}
+			}
+		})
+	}
+}
+
+func TestPlan(t *testing.T) {
+	if _, err := Plan(1, Latest()); err != nil {
+		t.Fatalf("Plan(1, latest): %v", err)
+	}
+	if _, err := Plan(Latest()+1, Latest()+1); err == nil {
+		t.Fatalf("expected error for unsupported version")
+	}
+	if _, err := Plan(2, 1); err == nil {
+		t.Fatalf("expected error for downgrade")
+	}
+}
+
+func TestMigrate_RejectsBadVersion(t *testing.T) {
+	for _, src := range []string{"system: {name: a}\n", "version: one\n", "version: 1.5\n"} {
+		if _, err := Migrate([]byte(src), 0); err == nil {
+			t.Errorf("expected error for %q", src)
+		}
+	}
+}
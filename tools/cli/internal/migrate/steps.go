@@ -0,0 +1,18 @@
+package migrate
+
+import "gopkg.in/yaml.v3"
+
+// Register new steps here. Every step needs fixtures under testdata/<step name>/.
+func init() {
+	Register(Step{
+		Name:  "v1-identity",
+		From:  1,
+		To:    1,
+		Apply: v1Identity,
+	})
+}
+
+// v1Identity keeps a v1 document as-is apart from writing `version` as a plain integer.
+func v1Identity(root *yaml.Node) error {
+	return SetVersion(root, 1)
+}
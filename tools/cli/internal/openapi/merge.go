@@ -0,0 +1,338 @@
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/yamledit"
+	"gopkg.in/yaml.v3"
+)
+
+// Merge proposes an updated map: entrypoints missing from
+// boundaries.entrypoints.http are appended to it and ownership.docs.openapi is
+// set to specPath. Existing entries are kept. Only the touched lines change;
+// the rest of the file (comments, indentation, quoting) is left as written.
+func Merge(src []byte, entrypoints []string, specPath string) ([]byte, error) {
+	out := src
+	var err error
+	if len(entrypoints) > 0 {
+		if out, err = addEntrypoints(out, entrypoints); err != nil {
+			return nil, err
+		}
+	}
+	if specPath != "" {
+		if out, err = setSpecPath(out, specPath); err != nil {
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+var (
+	httpKeys = []string{"boundaries", "entrypoints", "http"}
+	specKeys = []string{"ownership", "docs", "openapi"}
+)
+
+func addEntrypoints(src []byte, entrypoints []string) ([]byte, error) {
+	e, err := parseForEdit(src)
+	if err != nil {
+		return nil, err
+	}
+	items := func(indent string, have []string) []string {
+		var out []string
+		for _, ep := range entrypoints {
+			if !contains(have, ep) {
+				have = append(have, ep)
+				out = append(out, indent+"- "+quote(ep))
+			}
+		}
+		return out
+	}
+	key, v, err := e.find(httpKeys, func(indent string) []string {
+		return append([]string{indent + "http:"}, items(indent+e.unit, nil)...)
+	})
+	if err != nil || key == nil {
+		return e.bytes(), err
+	}
+
+	var have []string
+	for _, it := range v.Content {
+		have = append(have, it.Value)
+	}
+	switch {
+	case isNull(v):
+		if v.Value != "" {
+			return nil, fmt.Errorf("%s is %s; make it a list or edit the map by hand", dotted(httpKeys), v.Value)
+		}
+		e.insert(key.Line, items(strings.Repeat(" ", key.Column-1)+e.unit, nil))
+	case v.Kind == yaml.SequenceNode && v.Style&yaml.FlowStyle != 0:
+		line := e.lines[v.Line-1]
+		start := v.Column - 1
+		end := closing(line, start)
+		if end < 0 {
+			return nil, fmt.Errorf("%s spans several lines; make it a block list or edit the map by hand", dotted(httpKeys))
+		}
+		var add []string
+		for _, it := range items("", have) {
+			add = append(add, strings.TrimPrefix(it, "- "))
+		}
+		if len(add) == 0 {
+			break
+		}
+		inner := strings.TrimRight(line[start+1:end], " ")
+		if strings.TrimSpace(inner) == "" {
+			inner = ""
+		} else {
+			inner += ", "
+		}
+		e.lines[v.Line-1] = line[:start+1] + inner + strings.Join(add, ", ") + line[end:]
+	case v.Kind == yaml.SequenceNode:
+		first := v.Content[0]
+		prefix := e.lines[first.Line-1][:first.Column-1]
+		if strings.TrimSpace(prefix) != "-" {
+			return nil, fmt.Errorf("%s: unsupported list layout; edit the map by hand", dotted(httpKeys))
+		}
+		indent := strings.TrimSuffix(strings.TrimRight(prefix, " "), "-")
+		e.insert(e.blockEnd(key), items(indent, have))
+	default:
+		return nil, fmt.Errorf("%s must be a list", dotted(httpKeys))
+	}
+	return e.bytes(), nil
+}
+
+func setSpecPath(src []byte, specPath string) ([]byte, error) {
+	e, err := parseForEdit(src)
+	if err != nil {
+		return nil, err
+	}
+	key, v, err := e.find(specKeys, func(indent string) []string {
+		return []string{indent + "openapi: " + quote(specPath)}
+	})
+	if err != nil || key == nil {
+		return e.bytes(), err
+	}
+	if v.Kind != yaml.ScalarNode || v.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return nil, fmt.Errorf("%s must be a single-line string", dotted(specKeys))
+	}
+	if v.Value == specPath {
+		return e.bytes(), nil
+	}
+	line := e.lines[v.Line-1]
+	if isNull(v) && v.Value == "" {
+		// "openapi:" with no value: write one after the colon.
+		colon := key.Column - 1 + strings.Index(line[key.Column-1:], ":")
+		e.lines[v.Line-1] = line[:colon+1] + " " + quote(specPath) + line[colon+1:]
+		return e.bytes(), nil
+	}
+	start := v.Column - 1
+	end := yamledit.ScalarEnd(line, start, v)
+	if end < 0 {
+		return nil, fmt.Errorf("%s: unsupported value layout; edit the map by hand", dotted(specKeys))
+	}
+	e.lines[v.Line-1] = line[:start] + quote(specPath) + line[end:]
+	return e.bytes(), nil
+}
+
+// editor patches a YAML document line by line, using the parsed node tree
+// only to locate keys.
+type editor struct {
+	root  *yaml.Node
+	lines []string
+	// unit is the document's indentation step.
+	unit string
+}
+
+func parseForEdit(src []byte) (*editor, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(src, &doc); err != nil {
+		return nil, fmt.Errorf("YAML parse error: %w", err)
+	}
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+		return nil, errors.New("top-level document must be a mapping/object")
+	}
+	root := doc.Content[0]
+	if root.Style&yaml.FlowStyle != 0 {
+		return nil, errors.New("flow-style top-level mapping; edit the map by hand")
+	}
+	unit := indentUnit(root)
+	if unit <= 0 {
+		unit = 2
+	}
+	return &editor{
+		root:  root,
+		lines: strings.Split(strings.TrimSuffix(string(src), "\n"), "\n"),
+		unit:  strings.Repeat(" ", unit),
+	}, nil
+}
+
+func (e *editor) bytes() []byte {
+	return []byte(strings.Join(e.lines, "\n") + "\n")
+}
+
+// insert adds lines before the 0-based line index at.
+func (e *editor) insert(at int, lines []string) {
+	if len(lines) == 0 {
+		return
+	}
+	e.lines = append(e.lines[:at], append(lines, e.lines[at:]...)...)
+}
+
+// find returns the key and value nodes at keys. When a level is missing it
+// writes the missing keys instead, with leaf rendering the last one at the
+// given indentation, and returns nil nodes.
+func (e *editor) find(keys []string, leaf func(indent string) []string) (key, value *yaml.Node, err error) {
+	cur := e.root
+	var curKey *yaml.Node
+	for i, k := range keys {
+		if cur.Kind != yaml.MappingNode {
+			return nil, nil, fmt.Errorf("%s must be an object", dotted(keys[:i]))
+		}
+		if cur.Style&yaml.FlowStyle != 0 {
+			return nil, nil, fmt.Errorf("%s is a flow-style mapping; edit the map by hand", dotted(keys[:i]))
+		}
+		kn, v := lookup(cur, k)
+		if kn == nil {
+			at, indent := e.lastLine()+1, 0
+			if curKey != nil {
+				at = e.blockEnd(curKey)
+				indent = curKey.Column - 1 + len(e.unit)
+			}
+			if len(cur.Content) > 0 {
+				indent = cur.Content[0].Column - 1
+			}
+			e.insert(at, e.block(keys[i:], indent, leaf))
+			return nil, nil, nil
+		}
+		if i == len(keys)-1 {
+			return kn, v, nil
+		}
+		if isNull(v) {
+			if v.Value != "" {
+				return nil, nil, fmt.Errorf("%s is %s; make it an object or edit the map by hand", dotted(keys[:i+1]), v.Value)
+			}
+			e.insert(kn.Line, e.block(keys[i+1:], kn.Column-1+len(e.unit), leaf))
+			return nil, nil, nil
+		}
+		cur, curKey = v, kn
+	}
+	return nil, nil, nil
+}
+
+// block renders nested keys, the last through leaf.
+func (e *editor) block(keys []string, indent int, leaf func(indent string) []string) []string {
+	var out []string
+	for _, k := range keys[:len(keys)-1] {
+		out = append(out, strings.Repeat(" ", indent)+k+":")
+		indent += len(e.unit)
+	}
+	return append(out, leaf(strings.Repeat(" ", indent))...)
+}
+
+// blockEnd returns the 0-based index just past the last non-blank line of
+// key's value.
+func (e *editor) blockEnd(key *yaml.Node) int {
+	keyIndent := key.Column - 1
+	end := key.Line
+	for i := key.Line; i < len(e.lines); i++ {
+		t := strings.TrimLeft(e.lines[i], " ")
+		if t == "" {
+			continue
+		}
+		indent := len(e.lines[i]) - len(t)
+		// A mapping's block list may sit at the key's own indentation.
+		if indent < keyIndent || (indent == keyIndent && !strings.HasPrefix(t, "-")) {
+			break
+		}
+		end = i + 1
+	}
+	return end
+}
+
+func (e *editor) lastLine() int {
+	i := len(e.lines) - 1
+	for i >= 0 && strings.TrimSpace(e.lines[i]) == "" {
+		i--
+	}
+	return i
+}
+
+// indentUnit finds the first nested block mapping's indentation step.
+func indentUnit(m *yaml.Node) int {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		k, v := m.Content[i], m.Content[i+1]
+		if v.Kind == yaml.MappingNode && v.Style&yaml.FlowStyle == 0 && len(v.Content) > 0 {
+			if d := v.Content[0].Column - k.Column; d > 0 {
+				return d
+			}
+		}
+	}
+	for i := 1; i < len(m.Content); i += 2 {
+		if v := m.Content[i]; v.Kind == yaml.MappingNode {
+			if d := indentUnit(v); d > 0 {
+				return d
+			}
+		}
+	}
+	return 0
+}
+
+func lookup(m *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i], m.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+func isNull(n *yaml.Node) bool {
+	return n.Kind == yaml.ScalarNode && n.Tag == "!!null"
+}
+
+// closing returns the index of the "]" closing the flow sequence opened at
+// start, or -1 when it is not on this line.
+func closing(line string, start int) int {
+	var inQuote byte
+	for i := start + 1; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case inQuote != 0:
+			if c == inQuote {
+				inQuote = 0
+			}
+		case c == '"' || c == '\'':
+			inQuote = c
+		case c == ']':
+			return i
+		case c == '[' || c == '{':
+			return -1
+		}
+	}
+	return -1
+}
+
+// quote renders s as a YAML scalar, quoted only when it has to be.
+func quote(s string) string {
+	b, err := yaml.Marshal(s)
+	if err != nil {
+		return fmt.Sprintf("%q", s)
+	}
+	return strings.TrimSuffix(string(b), "\n")
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func dotted(keys []string) string {
+	if len(keys) == 0 {
+		return "document"
+	}
+	return strings.Join(keys, ".")
+}
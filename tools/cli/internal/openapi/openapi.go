@@ -0,0 +1,118 @@
+// Package openapi reads local OpenAPI 3 and Swagger 2 documents and derives
+// boundaries.entrypoints.http suggestions from them.
+package openapi
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// HandlerKeys are the operation (or path item) extensions read as explicit
+// handler hints, in order of preference. Values name a source file, optionally
+// followed by "#symbol" or ":line".
+var HandlerKeys = []string{"x-handler", "x-handler-file", "x-source", "x-source-file"}
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Operation is one method on one path.
+type Operation struct {
+	Method      string
+	Path        string
+	OperationID string
+	// Handler is the explicit handler hint, if any (see HandlerKeys).
+	Handler string
+}
+
+func (o Operation) String() string {
+	s := strings.ToUpper(o.Method) + " " + o.Path
+	if o.OperationID != "" {
+		s += " (" + o.OperationID + ")"
+	}
+	return s
+}
+
+// Spec is the part of an OpenAPI document the importer uses.
+type Spec struct {
+	// Version is the openapi or swagger field, e.g. "3.0.3" or "2.0".
+	Version    string
+	Operations []Operation
+}
+
+// Parse decodes a YAML or JSON OpenAPI 3 / Swagger 2 document.
+func Parse(b []byte) (*Spec, error) {
+	var doc struct {
+		OpenAPI string                    `yaml:"openapi"`
+		Swagger string                    `yaml:"swagger"`
+		Paths   map[string]map[string]any `yaml:"paths"`
+	}
+	if err := yaml.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("YAML parse error: %w", err)
+	}
+	s := &Spec{}
+	switch {
+	case strings.HasPrefix(doc.OpenAPI, "3."):
+		s.Version = doc.OpenAPI
+	case doc.Swagger == "2.0":
+		s.Version = doc.Swagger
+	default:
+		return nil, errors.New("not an OpenAPI 3 or Swagger 2 document (missing openapi: 3.x or swagger: \"2.0\")")
+	}
+
+	for path, item := range doc.Paths {
+		itemHint := hint(item)
+		for _, m := range methods {
+			op, ok := item[m].(map[string]any)
+			if !ok {
+				continue
+			}
+			o := Operation{Method: m, Path: path, Handler: hint(op)}
+			o.OperationID, _ = op["operationId"].(string)
+			if o.Handler == "" {
+				o.Handler = itemHint
+			}
+			s.Operations = append(s.Operations, o)
+		}
+	}
+	sort.Slice(s.Operations, func(i, j int) bool {
+		a, b := s.Operations[i], s.Operations[j]
+		if a.Path != b.Path {
+			return a.Path < b.Path
+		}
+		return methodIndex(a.Method) < methodIndex(b.Method)
+	})
+	return s, nil
+}
+
+func hint(m map[string]any) string {
+	for _, k := range HandlerKeys {
+		if v, ok := m[k].(string); ok && strings.TrimSpace(v) != "" {
+			return strings.TrimSpace(v)
+		}
+	}
+	return ""
+}
+
+func methodIndex(m string) int {
+	for i, x := range methods {
+		if x == m {
+			return i
+		}
+	}
+	return len(methods)
+}
+
+// HintFile returns the file part of a handler hint: "src/users.ts#getUser" and
+// "src/users.go:42" both name "src/users.*".
+func HintFile(h string) string {
+	if i := strings.Index(h, "#"); i >= 0 {
+		h = h[:i]
+	}
+	if i := strings.LastIndex(h, ":"); i >= 0 && !strings.Contains(h[i:], "/") {
+		h = h[:i]
+	}
+	return strings.TrimSpace(h)
+}
@@ -0,0 +1,176 @@
+package openapi
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func write(t *testing.T, root, rel, body string) {
+	t.Helper()
+	p := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestParse(t *testing.T) {
+	s, err := Parse([]byte(`{"swagger": "2.0", "paths": {"/pets": {"post": {"operationId": "addPet"}, "get": {"operationId": "listPets"}}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s.Version != "2.0" || len(s.Operations) != 2 || s.Operations[0].Method != "get" {
+		t.Fatalf("unexpected spec: %+v", s)
+	}
+	if _, err := Parse([]byte("openapi: 2.0\n")); err == nil {
+		t.Fatal("expected an error for a non-OpenAPI document")
+	}
+}
+
+func TestHintFile(t *testing.T) {
+	for in, want := range map[string]string{
+		"src/users.ts#getUser": "src/users.ts",
+		"src/users.go:42":      "src/users.go",
+		"handlers/a.py":        "handlers/a.py",
+	} {
+		if got := HintFile(in); got != want {
+			t.Errorf("HintFile(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestSuggest(t *testing.T) {
+	root := t.TempDir()
+	write(t, root, "api/openapi.yaml", `openapi: 3.0.3
+paths:
+  /users:
+    get:
+      operationId: list_users
+    post:
+      operationId: createUser
+      x-handler: src/create.ts#createUser
+  /users/{id}:
+    get:
+      operationId: getUser
+  /health:
+    get:
+      summary: no operationId
+  /orders:
+    get:
+      operationId: listOrders
+`)
+	write(t, root, "src/users.go", "package src\n\nfunc (h *Handler) ListUsers(w http.ResponseWriter, r *http.Request) {}\n")
+	write(t, root, "src/users.ts", "export const getUser = async (req, res) => {}\n")
+	write(t, root, "src/create.ts", "export function createUser() {}\n")
+	write(t, root, "src/users_test.go", "func GetUser() {}\n")
+	// listOrders lives in a nested system and must not be attributed here.
+	write(t, root, "orders/.ai-map.yaml", "version: 1\n")
+	write(t, root, "orders/handlers.js", "function listOrders() {}\n")
+
+	spec, err := Parse([]byte(mustRead(t, filepath.Join(root, "api/openapi.yaml"))))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sug, err := Suggest(spec, root, filepath.Join(root, "api"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"src/create.ts", "src/users.go", "src/users.ts"}; !reflect.DeepEqual(sug.Entrypoints, want) {
+		t.Fatalf("entrypoints = %v, want %v", sug.Entrypoints, want)
+	}
+	var unmatched []string
+	for _, op := range sug.Unmatched {
+		unmatched = append(unmatched, op.Path)
+	}
+	if want := []string{"/health", "/orders"}; !reflect.DeepEqual(unmatched, want) {
+		t.Fatalf("unmatched = %v, want %v", unmatched, want)
+	}
+}
+
+func TestMerge(t *testing.T) {
+	src := `version: 1
+# identity
+system:
+  name: users
+boundaries:
+  entrypoints:
+    http:
+      - old/handler.go # hand-written
+    grpc: [proto/users.proto]
+`
+	out, err := Merge([]byte(src), []string{"src/users.go", "old/handler.go"}, "api/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `version: 1
+# identity
+system:
+  name: users
+boundaries:
+  entrypoints:
+    http:
+      - old/handler.go # hand-written
+      - src/users.go
+    grpc: [proto/users.proto]
+ownership:
+  docs:
+    openapi: api/openapi.yaml
+`
+	if string(out) != want {
+		t.Errorf("merged map:\n%s\nwant:\n%s", out, want)
+	}
+}
+
+func TestMergeEditsOnlyTouchedLines(t *testing.T) {
+	for _, tc := range []struct{ name, src, want string }{
+		{
+			name: "four-space indent keeps unmatched entries",
+			src:  "version: 1\nsystem:\n    name: users\nboundaries:\n    entrypoints:\n        http:\n        - legacy/health.go\n    critical:   [src/pay.go]\nownership:\n    team: core\n",
+			want: "version: 1\nsystem:\n    name: users\nboundaries:\n    entrypoints:\n        http:\n        - legacy/health.go\n        - src/users.go\n    critical:   [src/pay.go]\nownership:\n    team: core\n    docs:\n        openapi: api/openapi.yaml\n",
+		},
+		{
+			name: "flow mapping is refused",
+			src:  "version: 1\nboundaries:\n  entrypoints: {http: []}\n",
+			want: "",
+		},
+		{
+			name: "flow http list",
+			src:  "version: 1\nboundaries:\n  entrypoints:\n    http: [legacy/health.go]  # keep\nownership:\n  docs:\n    openapi: \"old.yaml\" # spec\n",
+			want: "version: 1\nboundaries:\n  entrypoints:\n    http: [legacy/health.go, src/users.go]  # keep\nownership:\n  docs:\n    openapi: api/openapi.yaml # spec\n",
+		},
+		{
+			name: "empty keys",
+			src:  "version: 1\nboundaries:\n  entrypoints:\n    http:\nownership:\n  docs:\n    openapi:\n",
+			want: "version: 1\nboundaries:\n  entrypoints:\n    http:\n      - src/users.go\nownership:\n  docs:\n    openapi: api/openapi.yaml\n",
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			out, err := Merge([]byte(tc.src), []string{"src/users.go"}, "api/openapi.yaml")
+			if tc.want == "" {
+				if err == nil {
+					t.Fatalf("flow-style mapping merged:\n%s", out)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(out) != tc.want {
+				t.Errorf("merged map:\n%s\nwant:\n%s", out, tc.want)
+			}
+		})
+	}
+}
+
+func mustRead(t *testing.T, p string) string {
+	t.Helper()
+	b, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
@@ -0,0 +1,175 @@
+package openapi
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/sources"
+)
+
+// maxSourceBytes skips generated or vendored blobs when indexing definitions.
+const maxSourceBytes = 1 << 20
+
+// Attribution sources for a Match.
+const (
+	ViaHint        = "hint"
+	ViaOperationID = "operationId"
+)
+
+// Match attributes an operation to a handler file.
+type Match struct {
+	Operation Operation
+	// File is relative to the map directory.
+	File string
+	Via  string
+}
+
+// Suggestion is the importer's proposal for one map.
+type Suggestion struct {
+	// Entrypoints are the distinct handler files, relative to the map directory, sorted.
+	Entrypoints []string
+	Matches     []Match
+	// Unmatched are operations no handler file was found for.
+	Unmatched []Operation
+	// Ambiguous are operations whose operationId is defined in several files;
+	// none of them is suggested.
+	Ambiguous map[string][]string
+}
+
+// Suggest attributes the spec's operations to files under mapDir. An explicit
+// handler hint wins when the file exists (relative to mapDir, then specDir);
+// otherwise the operationId is looked up among function and method definitions
+// in source files under mapDir, ignoring case and _/- separators. Nested maps'
+// directories belong to other systems and are not searched.
+func Suggest(s *Spec, mapDir, specDir string) (Suggestion, error) {
+	var sug Suggestion
+	var defs map[string][]string
+	for _, op := range s.Operations {
+		if f, ok := resolveHint(op.Handler, mapDir, specDir); ok {
+			sug.Matches = append(sug.Matches, Match{Operation: op, File: f, Via: ViaHint})
+			continue
+		}
+		if op.OperationID == "" {
+			sug.Unmatched = append(sug.Unmatched, op)
+			continue
+		}
+		if defs == nil {
+			var err error
+			if defs, err = indexDefinitions(mapDir); err != nil {
+				return Suggestion{}, err
+			}
+		}
+		switch files := defs[normalize(op.OperationID)]; len(files) {
+		case 0:
+			sug.Unmatched = append(sug.Unmatched, op)
+		case 1:
+			sug.Matches = append(sug.Matches, Match{Operation: op, File: files[0], Via: ViaOperationID})
+		default:
+			if sug.Ambiguous == nil {
+				sug.Ambiguous = map[string][]string{}
+			}
+			sug.Ambiguous[op.String()] = files
+		}
+	}
+
+	seen := map[string]bool{}
+	for _, m := range sug.Matches {
+		if !seen[m.File] {
+			seen[m.File] = true
+			sug.Entrypoints = append(sug.Entrypoints, m.File)
+		}
+	}
+	sort.Strings(sug.Entrypoints)
+	return sug, nil
+}
+
+// resolveHint returns the hinted file relative to mapDir, if it exists inside it.
+func resolveHint(h, mapDir, specDir string) (string, bool) {
+	f := HintFile(h)
+	if f == "" || strings.Contains(f, "://") {
+		return "", false
+	}
+	for _, base := range []string{mapDir, specDir} {
+		abs := filepath.Join(base, filepath.FromSlash(f))
+		if st, err := os.Stat(abs); err != nil || st.IsDir() {
+			continue
+		}
+		if rel, ok := aimap.RelTo(mapDir, abs); ok {
+			return rel, true
+		}
+	}
+	return "", false
+}
+
+// definition patterns capture a defined function or method name.
+var definitions = []*regexp.Regexp{
+	// Go, JS/TS, Python, Rust, PHP, Ruby, Kotlin, Scala, Swift functions.
+	regexp.MustCompile(`\b(?:func|function|def|fn|fun)\s+(?:\([^)]*\)\s*)?([A-Za-z_$][\w$]*)`),
+	// const getUser = async (req, res) => ... / = function
+	regexp.MustCompile(`\b(?:const|let|var)\s+([A-Za-z_$][\w$]*)\s*=\s*(?:async\s*)?(?:function\b|\([^)]*\)\s*=>|[A-Za-z_$][\w$]*\s*=>)`),
+	// Class methods (Java, C#, TS): an identifier, a parameter list and an opening brace.
+	regexp.MustCompile(`^\s*(?:(?:public|private|protected|internal|static|async|override|final|virtual|export)\s+)*(?:[\w<>\[\],.?]+\s+)?([A-Za-z_$][\w$]*)\s*\([^;]*\)\s*(?:throws\s+[\w., ]+)?\{`),
+}
+
+var notNames = map[string]bool{"if": true, "for": true, "while": true, "switch": true, "catch": true, "return": true, "function": true, "else": true}
+
+// indexDefinitions maps normalized names to the files (relative to root) defining them.
+func indexDefinitions(root string) (map[string][]string, error) {
+	inv, err := sources.Walk(root, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	defs := map[string][]string{}
+	for _, rel := range inv.Sources {
+		if sources.IsTest(rel) {
+			continue
+		}
+		names, err := definedNames(filepath.Join(root, filepath.FromSlash(rel)))
+		if err != nil {
+			continue
+		}
+		for n := range names {
+			defs[n] = append(defs[n], rel)
+		}
+	}
+	for _, list := range defs {
+		sort.Strings(list)
+	}
+	return defs, nil
+}
+
+func definedNames(p string) (map[string]bool, error) {
+	f, err := os.Open(p)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	if st, err := f.Stat(); err != nil || st.Size() > maxSourceBytes {
+		return nil, err
+	}
+	names := map[string]bool{}
+	sc := bufio.NewScanner(f)
+	sc.Buffer(make([]byte, 64*1024), maxSourceBytes)
+	for sc.Scan() {
+		line := sc.Text()
+		for _, re := range definitions {
+			for _, m := range re.FindAllStringSubmatch(line, -1) {
+				if !notNames[m[1]] {
+					names[normalize(m[1])] = true
+				}
+			}
+		}
+	}
+	return names, sc.Err()
+}
+
+// normalize folds case and separators so getUser, GetUser and get_user match.
+func normalize(s string) string {
+	return strings.NewReplacer("_", "", "-", "", ".", "").Replace(strings.ToLower(s))
+}
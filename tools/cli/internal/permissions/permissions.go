@@ -0,0 +1,182 @@
+// Package permissions turns a map's boundaries and ai-flow extension into per-path
+// decisions an agent harness can enforce before applying edits.
+package permissions
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+// Decision is what an agent may do with a path.
+type Decision string
+
+const (
+	Deny  Decision = "deny"
+	Read  Decision = "read"
+	Ask   Decision = "ask"
+	Write Decision = "write"
+)
+
+// ParseDecision parses a decision name.
+func ParseDecision(s string) (Decision, error) {
+	switch d := Decision(strings.ToLower(strings.TrimSpace(s))); d {
+	case Deny, Read, Ask, Write:
+		return d, nil
+	default:
+		return "", fmt.Errorf("unknown decision %q (expected deny|read|ask|write)", s)
+	}
+}
+
+// precedence breaks ties between equally specific rules: ignore beats
+// safe_write, which beats critical.
+func precedence(d Decision) int {
+	switch d {
+	case Deny:
+		return 3
+	case Write:
+		return 2
+	case Ask:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Rule grants a decision for a boundary pattern; Source names the map field it came from.
+type Rule struct {
+	Pattern  string
+	Decision Decision
+	Source   string
+}
+
+// Policy is the rule set derived from one map.
+type Policy struct {
+	// Default applies to paths inside the map's directory that no rule covers.
+	Default Decision
+	// Outside applies to paths outside the map's directory (other systems).
+	Outside Decision
+	Rules   []Rule
+}
+
+// Options tunes policy derivation.
+type Options struct {
+	// Default decision for uncovered paths (Write when empty).
+	Default Decision
+}
+
+// FromMap derives the policy from:
+//
+//	extensions.ai-flow.ignore     -> deny
+//	extensions.ai-flow.safe_write -> write (explicit permission, e.g. within a critical path)
+//	boundaries.critical           -> ask
+//
+// The most specific matching rule decides; see Decide.
+// Paths outside the map's directory are read-only.
+func FromMap(m *aimap.Map, opt Options) Policy {
+	p := Policy{Default: opt.Default, Outside: Read}
+	if p.Default == "" {
+		p.Default = Write
+	}
+	flow := m.AIFlow()
+	for _, c := range m.Boundaries.Critical {
+		p.Rules = append(p.Rules, Rule{Pattern: aimap.CleanPath(c), Decision: Ask, Source: "boundaries.critical"})
+	}
+	for _, s := range flow.SafeWrite {
+		p.Rules = append(p.Rules, Rule{Pattern: aimap.CleanPath(s), Decision: Write, Source: "extensions.ai-flow.safe_write"})
+	}
+	for _, s := range flow.Ignore {
+		p.Rules = append(p.Rules, Rule{Pattern: aimap.CleanPath(s), Decision: Deny, Source: "extensions.ai-flow.ignore"})
+	}
+	sort.SliceStable(p.Rules, func(i, j int) bool {
+		if p.Rules[i].Pattern != p.Rules[j].Pattern {
+			return p.Rules[i].Pattern < p.Rules[j].Pattern
+		}
+		return precedence(p.Rules[i].Decision) > precedence(p.Rules[j].Decision)
+	})
+	return p
+}
+
+// Result is the decision for one path and the rule that produced it (nil for defaults).
+type Result struct {
+	Path     string
+	Decision Decision
+	Rule     *Rule
+}
+
+// Decide returns the decision for rel, a path relative to the map's directory
+// (inside=false for paths outside it). Among matching rules the most specific
+// pattern wins, so a broad safe_write never unlocks a narrower critical path;
+// equally specific rules are ordered by precedence.
+func (p Policy) Decide(rel string, inside bool) Result {
+	if !inside {
+		return Result{Path: rel, Decision: p.Outside}
+	}
+	rel = aimap.CleanPath(rel)
+	var best *Rule
+	for i := range p.Rules {
+		r := &p.Rules[i]
+		if !aimap.MatchPath(r.Pattern, rel) {
+			continue
+		}
+		if best == nil {
+			best = r
+			continue
+		}
+		rs, bs := specificity(r.Pattern), specificity(best.Pattern)
+		if rs > bs || (rs == bs && precedence(r.Decision) > precedence(best.Decision)) {
+			best = r
+		}
+	}
+	if best == nil {
+		return Result{Path: rel, Decision: p.Default}
+	}
+	return Result{Path: rel, Decision: best.Decision, Rule: best}
+}
+
+// specificity counts the path segments a pattern pins down; "**" pins none.
+func specificity(pattern string) int {
+	if pattern == "." {
+		return 0
+	}
+	n := 0
+	for _, seg := range strings.Split(pattern, "/") {
+		if seg != "**" {
+			n++
+		}
+	}
+	return n
+}
+
+// Export builds the JSON-ready document consumed by agent harnesses.
+func Export(mapPath string, p Policy, results []Result) map[string]any {
+	rules := make([]any, 0, len(p.Rules))
+	for _, r := range p.Rules {
+		rules = append(rules, map[string]any{
+			"path":     r.Pattern,
+			"decision": string(r.Decision),
+			"source":   r.Source,
+		})
+	}
+	out := map[string]any{
+		"map":     mapPath,
+		"default": string(p.Default),
+		"outside": string(p.Outside),
+		"rules":   rules,
+	}
+	if results != nil {
+		ds := make([]any, 0, len(results))
+		for _, r := range results {
+			d := map[string]any{"path": r.Path, "decision": string(r.Decision)}
+			if r.Rule != nil {
+				d["rule"] = r.Rule.Pattern
+				d["source"] = r.Rule.Source
+			}
+			ds = append(ds, d)
+		}
+		out["decisions"] = ds
+	}
+	return out
+}
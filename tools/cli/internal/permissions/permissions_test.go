@@ -0,0 +1,77 @@
+package permissions
+
+import (
+	"testing"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+func TestDecide(t *testing.T) {
+	m, err := aimap.Parse([]byte(`version: 1
+system: {name: a}
+boundaries:
+  critical: [src/core, src/payments]
+extensions:
+  ai-flow:
+    ignore: [src/legacy, "**/*.gen.go"]
+    safe_write: [src/core/docs]
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	p := FromMap(m, Options{})
+
+	cases := []struct {
+		path   string
+		inside bool
+		want   Decision
+	}{
+		{"src/api/handler.go", true, Write},
+		{"src/core/engine.go", true, Ask},
+		{"src/core/docs/README.md", true, Write},
+		{"src/legacy/old.go", true, Deny},
+		{"src/api/types.gen.go", true, Deny},
+		{"./src/payments/", true, Ask},
+		{"../other/x.go", false, Read},
+	}
+	for _, c := range cases {
+		if got := p.Decide(c.path, c.inside); got.Decision != c.want {
+			t.Errorf("Decide(%q) = %s, want %s", c.path, got.Decision, c.want)
+		}
+	}
+
+	ro := FromMap(m, Options{Default: Read})
+	if got := ro.Decide("src/api/handler.go", true); got.Decision != Read {
+		t.Errorf("default read: got %s", got.Decision)
+	}
+}
+
+func TestDecide_MostSpecificRuleWins(t *testing.T) {
+	m, err := aimap.Parse([]byte(`version: 1
+system: {name: a}
+boundaries:
+  critical: [src/core, lib]
+extensions:
+  ai-flow:
+    safe_write: [src, lib]
+`))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	p := FromMap(m, Options{})
+
+	cases := []struct {
+		path string
+		want Decision
+	}{
+		{"src/core/engine.go", Ask},
+		{"src/api/handler.go", Write},
+		// Same pattern in both lists: precedence breaks the tie.
+		{"lib/util.go", Write},
+	}
+	for _, c := range cases {
+		if got := p.Decide(c.path, true); got.Decision != c.want {
+			t.Errorf("Decide(%q) = %s, want %s", c.path, got.Decision, c.want)
+		}
+	}
+}
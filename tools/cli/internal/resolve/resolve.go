@@ -0,0 +1,219 @@
+// Package resolve computes the effective map for a directory from nested maps.
+//
+// Resolution rules:
+//
+//   - The chain is every map from the repository root down to the directory,
+//     root first. The deepest map is the leaf.
+//   - ownership, runtime and dependencies are inherited field by field: a
+//     descendant's value for a field replaces its ancestors' (lists replace as a
+//     whole; objects merge key by key).
+//   - version, system, boundaries, extensions and any other key come from the
+//     leaf only.
+//   - Every path-valued field is rebased from the directory of the map that set
+//     it to the repository root.
+package resolve
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+// Inherited lists the top-level sections descendants inherit from ancestors.
+var Inherited = []string{"dependencies", "ownership", "runtime"}
+
+// Layer is one map in the chain, with repo-relative locations.
+type Layer struct {
+	Path string
+	Dir  string
+	Doc  map[string]any
+}
+
+type Effective struct {
+	Doc map[string]any
+	// Sources maps dotted field paths to the map file that provided them.
+	Sources map[string]string
+	// Chain lists the map files consulted, root first.
+	Chain []string
+}
+
+// Chain returns the absolute paths of map files in root and every directory
+// between root and dir (inclusive), root first. dir must be inside root.
+func Chain(root, dir string) ([]string, error) {
+	rel, err := filepath.Rel(root, dir)
+	if err != nil {
+		return nil, err
+	}
+	var dirs []string
+	cur := root
+	dirs = append(dirs, cur)
+	if rel != "." {
+		for _, seg := range strings.Split(filepath.ToSlash(rel), "/") {
+			cur = filepath.Join(cur, seg)
+			dirs = append(dirs, cur)
+		}
+	}
+	var out []string
+	for _, d := range dirs {
+		p := filepath.Join(d, aimap.FileName)
+		if st, err := os.Stat(p); err == nil && !st.IsDir() {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+
+// Resolve merges layers (root first) into the effective map.
+func Resolve(layers []Layer) Effective {
+	eff := Effective{Doc: map[string]any{}, Sources: map[string]string{}}
+	if len(layers) == 0 {
+		return eff
+	}
+	for _, l := range layers {
+		eff.Chain = append(eff.Chain, l.Path)
+	}
+
+	inherited := map[string]bool{}
+	for _, k := range Inherited {
+		inherited[k] = true
+	}
+
+	for _, l := range layers {
+		for _, k := range Inherited {
+			v, ok := l.Doc[k]
+			if !ok {
+				continue
+			}
+			merged, _ := eff.Doc[k].(map[string]any)
+			eff.Doc[k] = mergeInto(merged, rebaseSection(k, v, l.Dir), k, l.Path, eff.Sources)
+		}
+	}
+
+	leaf := layers[len(layers)-1]
+	keys := make([]string, 0, len(leaf.Doc))
+	for k := range leaf.Doc {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if inherited[k] {
+			continue
+		}
+		eff.Doc[k] = rebaseSection(k, leaf.Doc[k], leaf.Dir)
+		eff.Sources[k] = leaf.Path
+	}
+	return eff
+}
+
+// mergeInto overlays src onto dst key by key, recording the source of each leaf.
+func mergeInto(dst map[string]any, src any, prefix, from string, sources map[string]string) any {
+	sm, ok := src.(map[string]any)
+	if !ok {
+		dropSources(sources, prefix)
+		sources[prefix] = from
+		return src
+	}
+	if dst == nil {
+		dst = map[string]any{}
+	}
+	for k, v := range sm {
+		p := prefix + "." + k
+		if _, isMap := v.(map[string]any); isMap {
+			existing, _ := dst[k].(map[string]any)
+			delete(sources, p)
+			dst[k] = mergeInto(existing, v, p, from, sources)
+			continue
+		}
+		dropSources(sources, p)
+		dst[k] = v
+		sources[p] = from
+	}
+	return dst
+}
+
+func dropSources(sources map[string]string, prefix string) {
+	for k := range sources {
+		if k == prefix || strings.HasPrefix(k, prefix+".") {
+			delete(sources, k)
+		}
+	}
+}
+
+// rebaseSection rewrites the path-valued fields of a top-level section.
+func rebaseSection(key string, v any, dir string) any {
+	if dir == "." || dir == "" {
+		return v
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return v
+	}
+	out := make(map[string]any, len(m))
+	for k, val := range m {
+		out[k] = val
+	}
+	switch key {
+	case "boundaries":
+		if eps, ok := out["entrypoints"].(map[string]any); ok {
+			re := make(map[string]any, len(eps))
+			for proto, paths := range eps {
+				re[proto] = rebaseList(paths, dir)
+			}
+			out["entrypoints"] = re
+		}
+		out["models"] = rebaseList(out["models"], dir)
+		out["critical"] = rebaseList(out["critical"], dir)
+	case "runtime":
+		out["config_paths"] = rebaseList(out["config_paths"], dir)
+	case "ownership":
+		if docs, ok := out["docs"].(map[string]any); ok {
+			re := make(map[string]any, len(docs))
+			for k, val := range docs {
+				re[k] = rebaseOne(val, dir)
+			}
+			out["docs"] = re
+		}
+	case "extensions":
+		if flow, ok := out["ai-flow"].(map[string]any); ok {
+			re := make(map[string]any, len(flow))
+			for k, val := range flow {
+				re[k] = val
+			}
+			re["ignore"] = rebaseList(re["ignore"], dir)
+			re["safe_write"] = rebaseList(re["safe_write"], dir)
+			out["ai-flow"] = re
+		}
+	}
+	for k, val := range out {
+		if val == nil {
+			if _, had := m[k]; !had {
+				delete(out, k)
+			}
+		}
+	}
+	return out
+}
+
+func rebaseList(v any, dir string) any {
+	l, ok := v.([]any)
+	if !ok {
+		return v
+	}
+	out := make([]any, len(l))
+	for i, it := range l {
+		out[i] = rebaseOne(it, dir)
+	}
+	return out
+}
+
+func rebaseOne(v any, dir string) any {
+	s, ok := v.(string)
+	if !ok || strings.Contains(s, "://") || strings.HasPrefix(s, "/") {
+		return v
+	}
+	return path.Join(dir, aimap.CleanPath(s))
+}
@@ -0,0 +1,51 @@
+package resolve
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+)
+
+func TestResolve(t *testing.T) {
+	decode := func(s string) map[string]any {
+		d, err := aimap.Decode([]byte(s))
+		if err != nil {
+			t.Fatalf("Decode: %v", err)
+		}
+		return d
+	}
+	layers := []Layer{
+		{Path: ".ai-map.yaml", Dir: ".", Doc: decode(`version: 1
+system: {name: root}
+boundaries: {critical: [src/core]}
+ownership: {team: platform, docs: {runbook: docs/run.md}}
+runtime: {environment: lambda, config_paths: [infra]}
+`)},
+		{Path: "svc/b/.ai-map.yaml", Dir: "svc/b", Doc: decode(`version: 1
+system: {name: b}
+boundaries: {critical: [core]}
+ownership: {team: bteam}
+runtime: {config_paths: [config.yaml]}
+`)},
+	}
+
+	eff := Resolve(layers)
+
+	want := map[string]any{
+		"version":    1,
+		"system":     map[string]any{"name": "b"},
+		"boundaries": map[string]any{"critical": []any{"svc/b/core"}},
+		"ownership":  map[string]any{"team": "bteam", "docs": map[string]any{"runbook": "docs/run.md"}},
+		"runtime":    map[string]any{"environment": "lambda", "config_paths": []any{"svc/b/config.yaml"}},
+	}
+	if !reflect.DeepEqual(eff.Doc, want) {
+		t.Fatalf("effective doc mismatch:\n got %#v\nwant %#v", eff.Doc, want)
+	}
+	if eff.Sources["ownership.team"] != "svc/b/.ai-map.yaml" || eff.Sources["runtime.environment"] != ".ai-map.yaml" {
+		t.Fatalf("unexpected sources %#v", eff.Sources)
+	}
+	if len(eff.Chain) != 2 {
+		t.Fatalf("unexpected chain %v", eff.Chain)
+	}
+}
@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//go:embed extensions/*.json
+var extensionsFS embed.FS
+
+var builtinExtensions = loadExtensions()
+
+func loadExtensions() map[string][]byte {
+	out := map[string][]byte{}
+	ents, err := fs.ReadDir(extensionsFS, "extensions")
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range ents {
+		b, err := extensionsFS.ReadFile("extensions/" + e.Name())
+		if err != nil {
+			panic(err)
+		}
+		out[strings.TrimSuffix(e.Name(), ".json")] = b
+	}
+	return out
+}
+
+// Extensions returns the names of extensions with a built-in schema, sorted.
+func Extensions() []string {
+	out := make([]string, 0, len(builtinExtensions))
+	for n := range builtinExtensions {
+		out = append(out, n)
+	}
+	sort.Strings(out)
+	return out
+}
+
+// LookupExtension returns the built-in schema for an extension name.
+func LookupExtension(name string) ([]byte, bool) {
+	b, ok := builtinExtensions[name]
+	return b, ok
+}
+
+// ExtensionURL is the identifier a built-in extension schema is compiled under.
+func ExtensionURL(name string) string {
+	return fmt.Sprintf("embedded:///ai-map/extensions/%s.schema.json", name)
+}
+
+// extensionName is lowercase kebab-case, optionally namespaced with dots (e.g. "acme.deploy-gates").
+var extensionName = regexp.MustCompile(`^[a-z][a-z0-9]*(-[a-z0-9]+)*(\.[a-z][a-z0-9]*(-[a-z0-9]+)*)*$`)
+
+// CheckExtensionName reports whether name follows the extension naming convention.
+func CheckExtensionName(name string) error {
+	if !extensionName.MatchString(name) {
+		return fmt.Errorf("extension name %q must be lowercase kebab-case, optionally namespaced with dots (e.g. \"ai-flow\", \"acme.deploy-gates\")", name)
+	}
+	return nil
+}
@@ -0,0 +1,127 @@
+// Package schema embeds the JSON Schema for each supported AI-Map spec version.
+//
+// To support a new spec version, add specs/v<N>.json; it is picked up by
+// version number automatically.
+package schema
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed specs/*.json
+var specsFS embed.FS
+
+var byVersion = loadAll()
+
+func loadAll() map[int][]byte {
+	out := map[int][]byte{}
+	ents, err := fs.ReadDir(specsFS, "specs")
+	if err != nil {
+		panic(err)
+	}
+	for _, e := range ents {
+		name := strings.TrimSuffix(e.Name(), ".json")
+		v, err := strconv.Atoi(strings.TrimPrefix(name, "v"))
+		if err != nil || !strings.HasPrefix(name, "v") {
+			panic(fmt.Sprintf("schema: unexpected embedded file %q", e.Name()))
+		}
+		b, err := specsFS.ReadFile("specs/" + e.Name())
+		if err != nil {
+			panic(err)
+		}
+		out[v] = b
+	}
+	return out
+}
+
+// Versions returns the supported spec versions in ascending order.
+func Versions() []int {
+	out := make([]int, 0, len(byVersion))
+	for v := range byVersion {
+		out = append(out, v)
+	}
+	sort.Ints(out)
+	return out
+}
+
+// Latest returns the newest supported spec version.
+func Latest() int {
+	vs := Versions()
+	return vs[len(vs)-1]
+}
+
+// Lookup returns the embedded schema for a spec version.
+func Lookup(version int) ([]byte, bool) {
+	b, ok := byVersion[version]
+	return b, ok
+}
+
+// URL is the identifier the embedded schema for version is compiled under.
+func URL(version int) string {
+	return fmt.Sprintf("embedded:///ai-map/v%d.schema.json", version)
+}
+
+// Hash returns a digest over every embedded schema, including built-in extension
+// schemas; it changes whenever any of them does.
+func Hash() string {
+	h := sha256.New()
+	for _, v := range Versions() {
+		fmt.Fprintf(h, "v%d\n", v)
+		h.Write(byVersion[v])
+	}
+	for _, n := range Extensions() {
+		fmt.Fprintf(h, "ext %s\n", n)
+		h.Write(builtinExtensions[n])
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// VersionOf extracts the spec version from a decoded document.
+// It returns an error naming the problem when version is missing or not a whole number.
+func VersionOf(doc map[string]any) (int, error) {
+	raw, ok := doc["version"]
+	if !ok {
+		return 0, fmt.Errorf("missing required field: version")
+	}
+	var f float64
+	switch x := raw.(type) {
+	case int:
+		f = float64(x)
+	case int64:
+		f = float64(x)
+	case uint64:
+		f = float64(x)
+	case float64:
+		f = x
+	default:
+		return 0, fmt.Errorf("version must be a number, got %T", raw)
+	}
+	if math.Trunc(f) != f || f < 1 {
+		return 0, fmt.Errorf("version must be a positive whole number, got %v", raw)
+	}
+	return int(f), nil
+}
+
+// Supported reports whether version has an embedded schema.
+func Supported(version int) bool {
+	_, ok := byVersion[version]
+	return ok
+}
+
+// UnsupportedError formats the standard message for an unknown spec version.
+func UnsupportedError(version int) error {
+	vs := Versions()
+	parts := make([]string, len(vs))
+	for i, v := range vs {
+		parts[i] = strconv.Itoa(v)
+	}
+	return fmt.Errorf("unsupported spec version %d (supported: %s)", version, strings.Join(parts, ", "))
+}
@@ -0,0 +1,148 @@
+// Package sources inventories the source files a map's system owns: files under
+// its directory that git would track, outside nested maps and the map's
+// extensions.ai-flow.ignore paths.
+package sources
+
+import (
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/discover"
+)
+
+// Languages maps source file extensions to system.language values.
+var Languages = map[string]string{
+	".go":    "go",
+	".ts":    "typescript",
+	".tsx":   "typescript",
+	".mts":   "typescript",
+	".cts":   "typescript",
+	".js":    "javascript",
+	".jsx":   "javascript",
+	".mjs":   "javascript",
+	".cjs":   "javascript",
+	".py":    "python",
+	".rb":    "ruby",
+	".java":  "java",
+	".kt":    "kotlin",
+	".kts":   "kotlin",
+	".scala": "scala",
+	".cs":    "csharp",
+	".fs":    "fsharp",
+	".php":   "php",
+	".rs":    "rust",
+	".swift": "swift",
+	".c":     "c",
+	".h":     "c",
+	".cc":    "cpp",
+	".cpp":   "cpp",
+	".hpp":   "cpp",
+	".ex":    "elixir",
+	".exs":   "elixir",
+	".erl":   "erlang",
+	".dart":  "dart",
+	".lua":   "lua",
+	".sh":    "shell",
+}
+
+// languageAliases are other spellings of Languages values seen in maps.
+var languageAliases = map[string]string{
+	"golang":     "go",
+	"ts":         "typescript",
+	"js":         "javascript",
+	"node":       "javascript",
+	"nodejs":     "javascript",
+	"py":         "python",
+	"python3":    "python",
+	"c#":         "csharp",
+	"dotnet":     "csharp",
+	"f#":         "fsharp",
+	"c++":        "cpp",
+	"bash":       "shell",
+	"ecmascript": "javascript",
+}
+
+// NormalizeLanguage returns the canonical form of a system.language value.
+func NormalizeLanguage(s string) string {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if a, ok := languageAliases[s]; ok {
+		return a
+	}
+	return s
+}
+
+// Language returns the language of a source file, or "" for other files.
+func Language(rel string) string {
+	return Languages[strings.ToLower(path.Ext(rel))]
+}
+
+// IsTest reports whether rel looks like a test file by name.
+func IsTest(rel string) bool {
+	base := path.Base(rel)
+	return strings.Contains(base, "_test.") || strings.Contains(base, ".test.") ||
+		strings.Contains(base, ".spec.") || strings.HasPrefix(base, "test_")
+}
+
+// Inventory is what a walk of a map's directory found.
+type Inventory struct {
+	// Files are every file the system owns, relative to the map directory, sorted.
+	Files []string
+	// Sources are the Files with a Language, tests included.
+	Sources []string
+	// Nested are the directories of nested maps, whose trees were skipped.
+	Nested []string
+}
+
+// Walk inventories mapDir for the map m (nil for no ai-flow ignore paths).
+func Walk(mapDir string, m *aimap.Map) (Inventory, error) {
+	var ignored []string
+	if m != nil {
+		ignored = m.AIFlow().Ignore
+	}
+	var inv Inventory
+	var all []string
+	err := discover.Walk(mapDir, discover.Options{}, func(abs, rel string, d fs.DirEntry) error {
+		if d.Name() == discover.DefaultName && path.Dir(rel) != "." {
+			inv.Nested = append(inv.Nested, path.Dir(rel))
+		}
+		all = append(all, rel)
+		return nil
+	})
+	if err != nil {
+		return Inventory{}, err
+	}
+	for _, rel := range all {
+		if under(rel, inv.Nested) || matchesAny(ignored, rel) {
+			continue
+		}
+		inv.Files = append(inv.Files, rel)
+		if Language(rel) != "" {
+			inv.Sources = append(inv.Sources, rel)
+		}
+	}
+	sort.Strings(inv.Files)
+	sort.Strings(inv.Sources)
+	sort.Strings(inv.Nested)
+	return inv, nil
+}
+
+func under(rel string, dirs []string) bool {
+	for _, d := range dirs {
+		if strings.HasPrefix(rel, d+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(patterns []string, rel string) bool {
+	for _, p := range patterns {
+		if aimap.MatchPath(p, rel) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,17 @@
+package textdiff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	a := []byte("a\nb\nc\nd\ne\nf\ng\nh\ni\nj\nk\n")
+	b := []byte("a\nB\nc\nd\ne\nf\ng\nh\ni\nj\nk\nl\n")
+	want := "--- old\n+++ new\n" +
+		"@@ -1,5 +1,5 @@\n a\n-b\n+B\n c\n d\n e\n" +
+		"@@ -9,3 +9,4 @@\n i\n j\n k\n+l\n"
+	if got := string(Unified("old", "new", a, b)); got != want {
+		t.Fatalf("got:\n%s\nwant:\n%s", got, want)
+	}
+	if Unified("a", "b", a, a) != nil {
+		t.Fatalf("expected nil diff for equal input")
+	}
+}
@@ -0,0 +1,130 @@
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/schema"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// UnknownExtensions controls how an `extensions.<name>` without a registered schema is reported.
+type UnknownExtensions string
+
+const (
+	UnknownExtensionsIgnore UnknownExtensions = "ignore"
+	UnknownExtensionsWarn   UnknownExtensions = "warn"
+	UnknownExtensionsError  UnknownExtensions = "error"
+)
+
+// ParseUnknownExtensions parses a policy name; empty means warn.
+func ParseUnknownExtensions(s string) (UnknownExtensions, error) {
+	switch p := UnknownExtensions(strings.ToLower(strings.TrimSpace(s))); p {
+	case "":
+		return UnknownExtensionsWarn, nil
+	case UnknownExtensionsIgnore, UnknownExtensionsWarn, UnknownExtensionsError:
+		return p, nil
+	default:
+		return "", fmt.Errorf("unknown extensions policy %q (expected ignore|warn|error)", s)
+	}
+}
+
+// loadExtensionSchemas compiles the built-in extension schemas, then any <name>.json
+// files in dir (which override built-ins of the same name).
+func loadExtensionSchemas(dir string) (map[string]*jsonschema.Schema, error) {
+	out := make(map[string]*jsonschema.Schema)
+	for _, name := range schema.Extensions() {
+		b, _ := schema.LookupExtension(name)
+		s, err := compileEmbedded(schema.ExtensionURL(name), b)
+		if err != nil {
+			return nil, fmt.Errorf("embedded extension schema %q: %w", name, err)
+		}
+		out[name] = s
+	}
+	if strings.TrimSpace(dir) == "" {
+		return out, nil
+	}
+
+	ents, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("cannot read extension schema dir %q: %w", dir, err)
+	}
+	for _, e := range ents {
+		if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+			continue
+		}
+		name := strings.TrimSuffix(e.Name(), ".json")
+		if err := schema.CheckExtensionName(name); err != nil {
+			return nil, fmt.Errorf("%s: %w", filepath.Join(dir, e.Name()), err)
+		}
+		s, err := loadSchemaFromFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("extension schema %q: %w", name, err)
+		}
+		out[name] = s
+	}
+	return out, nil
+}
+
+// checkExtensions validates each `extensions.<name>` block against its registered schema.
+func (v *Validator) checkExtensions(doc any) (errs, warns []string) {
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return nil, nil
+	}
+	raw, ok := m["extensions"]
+	if !ok {
+		return nil, nil
+	}
+	exts, ok := raw.(map[string]any)
+	if !ok {
+		// The base schema reports the type error.
+		return nil, nil
+	}
+
+	names := make([]string, 0, len(exts))
+	for n := range exts {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		loc := "/extensions/" + name
+		if err := schema.CheckExtensionName(name); err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %s", loc, err))
+			continue
+		}
+		s, ok := v.extensions[name]
+		if !ok {
+			msg := fmt.Sprintf("%s: no schema registered for extension %q", loc, name)
+			switch v.opt.UnknownExtensions {
+			case UnknownExtensionsError:
+				errs = append(errs, msg)
+			case UnknownExtensionsIgnore:
+			default:
+				warns = append(warns, msg)
+			}
+			continue
+		}
+		if err := s.Validate(exts[name]); err != nil {
+			errs = append(errs, prefixSchemaErrors(loc, err)...)
+		}
+	}
+	return errs, warns
+}
+
+func prefixSchemaErrors(loc string, err error) []string {
+	var ve *jsonschema.ValidationError
+	if !errors.As(err, &ve) {
+		return []string{fmt.Sprintf("%s: %s", loc, err)}
+	}
+	out := []string{fmt.Sprintf("%s%s: %s", loc, ve.InstanceLocation, ve.Message)}
+	for _, c := range ve.Causes {
+		out = append(out, fmt.Sprintf("%s%s: %s", loc, c.InstanceLocation, c.Message))
+	}
+	return out
+}
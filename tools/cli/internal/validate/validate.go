@@ -2,7 +2,8 @@ package validate
 
 import (
 	"bytes"
-	"encoding/json"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
@@ -11,6 +12,8 @@ import (
 	"path/filepath"
 	"strings"
 
+	"github.com/olddognewflex/ai-map/tools/cli/internal/aimap"
+	"github.com/olddognewflex/ai-map/tools/cli/internal/schema"
 	"github.com/santhosh-tekuri/jsonschema/v5"
 	"gopkg.in/yaml.v3"
 )
@@ -18,33 +21,109 @@ import (
 type Options struct {
 	// MaxBytes caps YAML input reads.
 	MaxBytes int64
-	// SchemaPath points to a JSON Schema file on disk.
+	// SchemaPath points to a JSON Schema file on disk. When set, it applies to every
+	// input regardless of its declared version. When empty, the embedded schema for
+	// each document's `version` is used.
 	SchemaPath string
+	// ExtensionsDir holds <name>.json schemas for `extensions.<name>` blocks. Built-in
+	// extension schemas (e.g. ai-flow) are always registered; files here override them.
+	ExtensionsDir string
+	// UnknownExtensions decides how extensions without a schema are reported (default warn).
+	UnknownExtensions UnknownExtensions
 }
 
 type Result struct {
-	OK     bool
-	Errors []string
+	OK       bool
+	Errors   []string
+	Warnings []string
+	// Version is the spec version the schema was selected for (0 when --schema was given
+	// or the version could not be determined).
+	Version int
 }
 
 type Validator struct {
-	schema *jsonschema.Schema
-	opt    Options
+	schema     *jsonschema.Schema
+	byVersion  map[int]*jsonschema.Schema
+	extensions map[string]*jsonschema.Schema
+	opt        Options
+	// fingerprint identifies everything besides the input that affects a Result.
+	fingerprint string
 }
 
 func New(opt Options) (*Validator, error) {
-	if strings.TrimSpace(opt.SchemaPath) == "" {
-		return nil, errors.New("schema path is required")
-	}
 	if opt.MaxBytes <= 0 {
 		return nil, errors.New("max bytes must be > 0")
 	}
-
-	s, err := loadSchemaFromFile(opt.SchemaPath)
+	policy, err := ParseUnknownExtensions(string(opt.UnknownExtensions))
+	if err != nil {
+		return nil, err
+	}
+	opt.UnknownExtensions = policy
+	exts, err := loadExtensionSchemas(opt.ExtensionsDir)
 	if err != nil {
 		return nil, err
 	}
-	return &Validator{schema: s, opt: opt}, nil
+	fp, err := fingerprint(opt)
+	if err != nil {
+		return nil, err
+	}
+
+	if strings.TrimSpace(opt.SchemaPath) != "" {
+		s, err := loadSchemaFromFile(opt.SchemaPath)
+		if err != nil {
+			return nil, err
+		}
+		return &Validator{schema: s, extensions: exts, opt: opt, fingerprint: fp}, nil
+	}
+
+	byVersion := make(map[int]*jsonschema.Schema)
+	for _, ver := range schema.Versions() {
+		b, _ := schema.Lookup(ver)
+		s, err := compileEmbedded(schema.URL(ver), b)
+		if err != nil {
+			return nil, fmt.Errorf("embedded schema v%d: %w", ver, err)
+		}
+		byVersion[ver] = s
+	}
+	return &Validator{byVersion: byVersion, extensions: exts, opt: opt, fingerprint: fp}, nil
+}
+
+// Fingerprint hashes the validator's configuration: embedded schemas, the --schema
+// override, extension schemas on disk and the unknown-extensions policy. Two validators
+// with the same fingerprint return the same Result for the same bytes.
+func (v *Validator) Fingerprint() string {
+	return v.fingerprint
+}
+
+func fingerprint(opt Options) (string, error) {
+	h := sha256.New()
+	fmt.Fprintf(h, "embedded %s\nunknown %s\nmax %d\n", schema.Hash(), opt.UnknownExtensions, opt.MaxBytes)
+	if strings.TrimSpace(opt.SchemaPath) != "" {
+		b, err := os.ReadFile(opt.SchemaPath)
+		if err != nil {
+			return "", fmt.Errorf("cannot read schema: %w", err)
+		}
+		fmt.Fprintf(h, "schema %d\n", len(b))
+		h.Write(b)
+	}
+	if strings.TrimSpace(opt.ExtensionsDir) != "" {
+		ents, err := os.ReadDir(opt.ExtensionsDir)
+		if err != nil {
+			return "", fmt.Errorf("cannot read extension schema dir %q: %w", opt.ExtensionsDir, err)
+		}
+		for _, e := range ents {
+			if e.IsDir() || !strings.HasSuffix(e.Name(), ".json") {
+				continue
+			}
+			b, err := os.ReadFile(filepath.Join(opt.ExtensionsDir, e.Name()))
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(h, "ext %s %d\n", e.Name(), len(b))
+			h.Write(b)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
 }
 
 func (v *Validator) ValidateFile(path string) (Result, error) {
@@ -52,21 +131,66 @@ func (v *Validator) ValidateFile(path string) (Result, error) {
 	if err != nil {
 		return Result{}, err
 	}
+	return v.ValidateBytes(b), nil
+}
 
+// ValidateBytes validates an in-memory YAML document.
+func (v *Validator) ValidateBytes(b []byte) Result {
 	var doc any
 	if err := yaml.Unmarshal(b, &doc); err != nil {
-		return Result{OK: false, Errors: []string{fmt.Sprintf("YAML parse error: %s", err)}}, nil
+		return Result{OK: false, Errors: []string{fmt.Sprintf("YAML parse error: %s", err)}}
 	}
-	jsonReady, err := yamlToJSONReady(doc)
+	jsonReady, err := aimap.Normalize(doc)
 	if err != nil {
-		return Result{OK: false, Errors: []string{fmt.Sprintf("YAML normalization error: %s", err)}}, nil
+		return Result{OK: false, Errors: []string{fmt.Sprintf("YAML normalization error: %s", err)}}
+	}
+
+	sch, ver, err := v.schemaFor(jsonReady)
+	if err != nil {
+		return Result{OK: false, Errors: []string{err.Error()}}
 	}
 
 	// Validate expects JSON-compatible types.
-	if err := v.schema.Validate(jsonReady); err != nil {
-		return Result{OK: false, Errors: flattenSchemaError(err)}, nil
+	var errs []string
+	if err := sch.Validate(jsonReady); err != nil {
+		errs = flattenSchemaError(err)
+	}
+	extErrs, warns := v.checkExtensions(jsonReady)
+	errs = append(errs, extErrs...)
+	return Result{OK: len(errs) == 0, Errors: errs, Warnings: warns, Version: ver}
+}
+
+// schemaFor picks the explicit schema if one was configured, otherwise the embedded
+// schema matching the document's declared version.
+func (v *Validator) schemaFor(doc any) (*jsonschema.Schema, int, error) {
+	if v.schema != nil {
+		return v.schema, 0, nil
+	}
+	m, ok := doc.(map[string]any)
+	if !ok {
+		return nil, 0, errors.New("top-level document must be a mapping/object")
+	}
+	ver, err := schema.VersionOf(m)
+	if err != nil {
+		return nil, 0, fmt.Errorf("cannot select schema: %w", err)
+	}
+	s, ok := v.byVersion[ver]
+	if !ok {
+		return nil, ver, schema.UnsupportedError(ver)
+	}
+	return s, ver, nil
+}
+
+func compileEmbedded(u string, b []byte) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	// Embedded schemas are self-contained; never resolve external refs.
+	compiler.LoadURL = func(raw string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("disallowed schema ref %q in embedded schema", raw)
+	}
+	if err := compiler.AddResource(u, bytes.NewReader(b)); err != nil {
+		return nil, fmt.Errorf("cannot add schema: %w", err)
 	}
-	return Result{OK: true}, nil
+	return compiler.Compile(u)
 }
 
 func loadSchemaFromFile(schemaPath string) (*jsonschema.Schema, error) {
@@ -145,64 +269,6 @@ func readFileWithLimit(path string, maxBytes int64) ([]byte, error) {
 	return os.ReadFile(path)
 }
 
-func yamlToJSONReady(v any) (any, error) {
-	switch x := v.(type) {
-	case nil, bool, string:
-		return x, nil
-	case int, int8, int16, int32, int64:
-		return x, nil
-	case uint, uint8, uint16, uint32, uint64:
-		return x, nil
-	case float32, float64:
-		return x, nil
-	case []any:
-		out := make([]any, 0, len(x))
-		for _, it := range x {
-			cv, err := yamlToJSONReady(it)
-			if err != nil {
-				return nil, err
-			}
-			out = append(out, cv)
-		}
-		return out, nil
-	case map[string]any:
-		out := make(map[string]any, len(x))
-		for k, v := range x {
-			cv, err := yamlToJSONReady(v)
-			if err != nil {
-				return nil, err
-			}
-			out[k] = cv
-		}
-		return out, nil
-	case map[any]any:
-		out := make(map[string]any, len(x))
-		for k, v := range x {
-			ks, ok := k.(string)
-			if !ok {
-				return nil, fmt.Errorf("non-string map key %T", k)
-			}
-			cv, err := yamlToJSONReady(v)
-			if err != nil {
-				return nil, err
-			}
-			out[ks] = cv
-		}
-		return out, nil
-	default:
-		// Fallback: attempt JSON roundtrip for scalar-ish types (e.g. time.Time) but keep it deterministic.
-		b, err := json.Marshal(x)
-		if err != nil {
-			return nil, fmt.Errorf("unsupported YAML value %T", x)
-		}
-		var out any
-		if err := json.Unmarshal(b, &out); err != nil {
-			return nil, fmt.Errorf("unsupported YAML value %T", x)
-		}
-		return out, nil
-	}
-}
-
 func flattenSchemaError(err error) []string {
 	// jsonschema/v5 returns a rich error type; stringifying is stable enough for an MVP.
 	// We normalize line endings later at the caller boundary by writing \n only.
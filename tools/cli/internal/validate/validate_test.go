@@ -3,6 +3,7 @@ package validate
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -57,3 +58,62 @@ func TestValidator_OKAndFail(t *testing.T) {
 }
 
 
+
+func TestValidator_EmbeddedSchemaByVersion(t *testing.T) {
+	v, err := New(Options{MaxBytes: 1 << 20})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	if res := v.ValidateBytes([]byte("version: 1\nsystem: {name: a}\n")); !res.OK || res.Version != 1 {
+		t.Fatalf("expected v1 ok, got %#v", res)
+	}
+	if res := v.ValidateBytes([]byte("version: 1\nsystem: {}\n")); res.OK {
+		t.Fatalf("expected v1 schema failure")
+	}
+
+	res := v.ValidateBytes([]byte("version: 99\nsystem: {name: a}\n"))
+	if res.OK || len(res.Errors) != 1 || !strings.Contains(res.Errors[0], "unsupported spec version 99") {
+		t.Fatalf("expected unsupported version error, got %#v", res)
+	}
+	res = v.ValidateBytes([]byte("system: {name: a}\n"))
+	if res.OK || len(res.Errors) != 1 || !strings.Contains(res.Errors[0], "missing required field: version") {
+		t.Fatalf("expected missing version error, got %#v", res)
+	}
+}
+
+func TestValidator_Extensions(t *testing.T) {
+	td := t.TempDir()
+	if err := os.WriteFile(filepath.Join(td, "acme.gates.json"), []byte(`{"type":"object","required":["gates"]}`), 0o644); err != nil {
+		t.Fatalf("write schema: %v", err)
+	}
+
+	doc := []byte(`version: 1
+system: {name: a}
+extensions:
+  ai-flow: {ignore: [src/legacy]}
+  acme.gates: {}
+  unknown-ext: {}
+`)
+
+	v, err := New(Options{MaxBytes: 1 << 20, ExtensionsDir: td})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	res := v.ValidateBytes(doc)
+	if res.OK || len(res.Errors) == 0 || !strings.HasPrefix(res.Errors[0], "/extensions/acme.gates") {
+		t.Fatalf("expected acme.gates failure, got %#v", res)
+	}
+	if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], "unknown-ext") {
+		t.Fatalf("expected one unknown-extension warning, got %#v", res.Warnings)
+	}
+
+	strict, err := New(Options{MaxBytes: 1 << 20, UnknownExtensions: UnknownExtensionsError})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	res = strict.ValidateBytes([]byte("version: 1\nsystem: {name: a}\nextensions:\n  Not_Kebab: {}\n  ai-flow: {safe_write: [src/core]}\n"))
+	if res.OK || len(res.Errors) != 1 || !strings.Contains(res.Errors[0], "kebab-case") {
+		t.Fatalf("expected naming error only, got %#v", res)
+	}
+}
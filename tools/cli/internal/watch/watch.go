@@ -0,0 +1,126 @@
+// Package watch detects file changes by polling. It needs no native notification
+// support, so it behaves the same on every platform and filesystem (including
+// network mounts and containers).
+package watch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/olddognewflex/ai-map/tools/cli/internal/discover"
+)
+
+// Stamp is what a poll compares: a change to any field counts as a modification.
+type Stamp struct {
+	ModTime time.Time
+	Size    int64
+	Mode    fs.FileMode
+}
+
+// Snapshot maps absolute file paths to their stamps. Missing paths are absent.
+type Snapshot map[string]Stamp
+
+// Take stats every path; directories are walked recursively with discover.Walk,
+// so .gitignore'd files and discover.SkipDirs are left out. Paths that do not
+// exist are simply not recorded.
+func Take(paths []string) Snapshot {
+	s := Snapshot{}
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			s[p] = stamp(info)
+			continue
+		}
+		_ = discover.Walk(p, discover.Options{}, func(abs, rel string, d fs.DirEntry) error {
+			if info, err := d.Info(); err == nil {
+				s[filepath.Join(p, filepath.FromSlash(rel))] = stamp(info)
+			}
+			return nil
+		})
+	}
+	return s
+}
+
+func stamp(info fs.FileInfo) Stamp {
+	return Stamp{ModTime: info.ModTime(), Size: info.Size(), Mode: info.Mode()}
+}
+
+// Diff returns the sorted paths added, removed or modified between s and next.
+func (s Snapshot) Diff(next Snapshot) []string {
+	var out []string
+	for p, st := range next {
+		if old, ok := s[p]; !ok || !old.ModTime.Equal(st.ModTime) || old.Size != st.Size || old.Mode != st.Mode {
+			out = append(out, p)
+		}
+	}
+	for p := range s {
+		if _, ok := next[p]; !ok {
+			out = append(out, p)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// Options tunes Loop.
+type Options struct {
+	// Interval between polls.
+	Interval time.Duration
+	// Debounce is how long the tree must stay unchanged before changes are reported,
+	// so a burst of saves (or a checkout) yields one callback.
+	Debounce time.Duration
+	// Ticks, when set, drives the polls instead of an Interval ticker; each
+	// value received is the poll's time.
+	Ticks <-chan time.Time
+}
+
+// Loop polls targets() every opt.Interval and calls fn with every path that changed
+// once the tree has been quiet for opt.Debounce. targets is re-evaluated each poll,
+// so the watched set may grow or shrink. Loop returns nil when ctx is cancelled.
+func Loop(ctx context.Context, opt Options, targets func() []string, fn func(changed []string)) error {
+	if opt.Interval <= 0 {
+		opt.Interval = 500 * time.Millisecond
+	}
+	prev := Take(targets())
+	pending := map[string]bool{}
+	var lastChange time.Time
+
+	ticks := opt.Ticks
+	if ticks == nil {
+		ticker := time.NewTicker(opt.Interval)
+		defer ticker.Stop()
+		ticks = ticker.C
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticks:
+			next := Take(targets())
+			if changed := prev.Diff(next); len(changed) > 0 {
+				for _, p := range changed {
+					pending[p] = true
+				}
+				lastChange = now
+			}
+			prev = next
+			if len(pending) == 0 || now.Sub(lastChange) < opt.Debounce {
+				continue
+			}
+			out := make([]string, 0, len(pending))
+			for p := range pending {
+				out = append(out, p)
+			}
+			sort.Strings(out)
+			pending = map[string]bool{}
+			fn(out)
+		}
+	}
+}
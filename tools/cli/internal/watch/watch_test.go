@@ -0,0 +1,98 @@
+package watch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	dir := t.TempDir()
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "src", "b.go")
+	skipped := filepath.Join(dir, "src", "node_modules", "x.js")
+	for _, p := range []string{a, b, skipped} {
+		if err := os.MkdirAll(filepath.Dir(p), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte("1"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ignored := filepath.Join(dir, "src", "build", "out.go")
+	if err := os.MkdirAll(filepath.Dir(ignored), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range map[string]string{ignored: "1", filepath.Join(dir, "src", ".gitignore"): "build/\n"} {
+		if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	targets := []string{a, filepath.Join(dir, "src"), filepath.Join(dir, "missing")}
+	s1 := Take(targets)
+	if len(s1) != 3 { // a, b and src/.gitignore
+		t.Fatalf("Take recorded %d files, want 3: %v", len(s1), s1)
+	}
+
+	if err := os.WriteFile(b, []byte("22"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Remove(a); err != nil {
+		t.Fatal(err)
+	}
+	c := filepath.Join(dir, "src", "c.go")
+	if err := os.WriteFile(c, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s1.Diff(Take(targets)), []string{a, b, c}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("Diff = %v, want %v", got, want)
+	}
+}
+
+func TestLoopDebouncesAndStops(t *testing.T) {
+	dir := t.TempDir()
+	p := filepath.Join(dir, "m.yaml")
+	if err := os.WriteFile(p, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each poll first calls targets, so the test edits the tree from there and
+	// the loop sees every edit at a known tick. Polls are 10ms apart.
+	start := time.Unix(0, 0)
+	var polls int
+	targets := func() []string {
+		if polls >= 1 && polls <= 5 { // a burst of writes on polls 1-5
+			if err := os.WriteFile(p, make([]byte, polls), 0o644); err != nil {
+				t.Error(err)
+			}
+		}
+		polls++
+		return []string{dir}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ticks := make(chan time.Time)
+	var calls []string
+	done := make(chan error, 1)
+	go func() {
+		done <- Loop(ctx, Options{Debounce: 60 * time.Millisecond, Ticks: ticks}, targets, func(changed []string) {
+			calls = append(calls, fmt.Sprintf("poll %d: %v", polls-1, changed))
+		})
+	}()
+	for i := 1; i <= 15; i++ {
+		ticks <- start.Add(time.Duration(i) * 10 * time.Millisecond)
+	}
+	cancel()
+	if err := <-done; err != nil {
+		t.Fatalf("Loop returned %v", err)
+	}
+
+	// The last write lands on poll 5 (50ms); the tree is quiet for 60ms at poll 11.
+	if want := []string{fmt.Sprintf("poll 11: %v", []string{p})}; !reflect.DeepEqual(calls, want) {
+		t.Fatalf("callbacks = %v, want %v", calls, want)
+	}
+}
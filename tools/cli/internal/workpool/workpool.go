@@ -0,0 +1,51 @@
+// Package workpool runs independent per-file work on a bounded number of
+// goroutines while keeping results in input order, so callers can print them
+// exactly as a serial loop would.
+package workpool
+
+import (
+	"runtime"
+	"sync"
+)
+
+// DefaultJobs is the worker count used when none is given: one per CPU.
+func DefaultJobs() int {
+	return runtime.NumCPU()
+}
+
+// Map calls fn for every item using at most jobs goroutines and returns the
+// results indexed like items. jobs < 1 is treated as 1; with one job the items
+// are processed serially on the calling goroutine.
+func Map[T, R any](jobs int, items []T, fn func(T) R) []R {
+	out := make([]R, len(items))
+	if jobs < 1 {
+		jobs = 1
+	}
+	if jobs > len(items) {
+		jobs = len(items)
+	}
+	if jobs <= 1 {
+		for i, it := range items {
+			out[i] = fn(it)
+		}
+		return out
+	}
+
+	next := make(chan int)
+	var wg sync.WaitGroup
+	wg.Add(jobs)
+	for w := 0; w < jobs; w++ {
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				out[i] = fn(items[i])
+			}
+		}()
+	}
+	for i := range items {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return out
+}
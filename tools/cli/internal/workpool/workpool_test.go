@@ -0,0 +1,36 @@
+package workpool
+
+import (
+	"sync/atomic"
+	"testing"
+)
+
+func TestMapKeepsOrderAndBound(t *testing.T) {
+	items := make([]int, 200)
+	for i := range items {
+		items[i] = i
+	}
+	var running, peak int32
+	got := Map(4, items, func(n int) int {
+		cur := atomic.AddInt32(&running, 1)
+		for {
+			p := atomic.LoadInt32(&peak)
+			if cur <= p || atomic.CompareAndSwapInt32(&peak, p, cur) {
+				break
+			}
+		}
+		defer atomic.AddInt32(&running, -1)
+		return n * n
+	})
+	for i, v := range got {
+		if v != i*i {
+			t.Fatalf("result %d = %d, want %d", i, v, i*i)
+		}
+	}
+	if peak > 4 {
+		t.Fatalf("peak concurrency %d exceeds 4 jobs", peak)
+	}
+	if len(Map(0, []int(nil), func(n int) int { return n })) != 0 {
+		t.Fatal("expected no results for no items")
+	}
+}
@@ -0,0 +1,39 @@
+// Package yamledit holds the helpers shared by commands that patch YAML files
+// line by line instead of re-encoding them.
+package yamledit
+
+import (
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ScalarEnd returns the index just past the scalar v written at start, or -1
+// when it does not end on this line.
+func ScalarEnd(line string, start int, v *yaml.Node) int {
+	switch {
+	case v.Style&yaml.DoubleQuotedStyle != 0:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\\' {
+				i++
+			} else if line[i] == '"' {
+				return i + 1
+			}
+		}
+	case v.Style&yaml.SingleQuotedStyle != 0:
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1
+			}
+		}
+	default:
+		if strings.HasPrefix(line[start:], v.Value) {
+			return start + len(v.Value)
+		}
+	}
+	return -1
+}